@@ -0,0 +1,14 @@
+//go:build !(linux || openbsd || dragonfly || solaris || darwin || freebsd || netbsd || windows)
+
+package xdg
+
+import (
+	"io/fs"
+	"time"
+)
+
+// accessTime returns the last access time of a file, if it's available.
+// It's not supported in this operating system.
+func accessTime(_ fs.FileInfo) (time.Time, bool) {
+	return time.Time{}, false
+}
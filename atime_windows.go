@@ -0,0 +1,17 @@
+package xdg
+
+import (
+	"io/fs"
+	"syscall"
+	"time"
+)
+
+// accessTime returns the last access time of a file, if it's available.
+// Note that NTFS updates it lazily, with a granularity of up to an hour.
+func accessTime(info fs.FileInfo) (time.Time, bool) {
+	d, ok := info.Sys().(*syscall.Win32FileAttributeData)
+	if !ok {
+		return time.Time{}, false
+	}
+	return time.Unix(0, d.LastAccessTime.Nanoseconds()), true
+}
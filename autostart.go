@@ -0,0 +1,169 @@
+package xdg
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+const autostartSubdir = "autostart"
+
+// EnableAutostart registers an application to start automatically when the
+// user logs in, according to the FreeDesktop.org Desktop Application Autostart
+// specification (https://specifications.freedesktop.org/autostart-spec/).
+// It writes the given desktop entry to the "autostart" subdirectory of
+// [ConfigHome], atomically, and returns its path. The entry's Hidden field is
+// ignored, so this overrides a system entry with the same ID which disables it.
+//
+// The entry's OnlyShowIn and NotShowIn fields may limit it to specific desktop
+// environments. Other fields and restrictions are like in [WriteDesktopEntry].
+func EnableAutostart(e *DesktopEntry) (string, error) {
+	return defaultResolver.EnableAutostart(e)
+}
+
+// EnableAutostart is like the package-level [EnableAutostart], but uses r's environment.
+func (r *Resolver) EnableAutostart(e *DesktopEntry) (string, error) {
+	if err := r.checkHost(); err != nil {
+		return "", err
+	}
+
+	enabled := *e
+	enabled.Hidden = false
+	return writeDesktopEntry(r.ConfigHome, autostartSubdir, &enabled)
+}
+
+// DisableAutostart unregisters an application with the given desktop file ID
+// (e.g. "org.example.App.desktop") from starting automatically when the user
+// logs in. It removes the entry from the "autostart" subdirectory of [ConfigHome],
+// and if there's also a system entry with the same ID in [ConfigDirs], it
+// overrides it with an entry whose Hidden key is true, as the autostart
+// specification requires. Disabling a non-existent entry is not an error.
+func DisableAutostart(id string) error {
+	return defaultResolver.DisableAutostart(id)
+}
+
+// DisableAutostart is like the package-level [DisableAutostart], but uses r's environment.
+func (r *Resolver) DisableAutostart(id string) error {
+	if err := r.checkHost(); err != nil {
+		return err
+	}
+	if err := checkDesktopFileID(id); err != nil {
+		return err
+	}
+
+	dirs, err := r.ConfigDirs()
+	if err != nil {
+		return err
+	}
+
+	sys, err := findAutostartEntry(dirs, id)
+	if err != nil {
+		return err
+	}
+
+	if sys != nil && !sys.Hidden {
+		name := sys.Name
+		if name == "" {
+			name = strings.TrimSuffix(id, desktopEntrySuffix)
+		}
+		_, err := writeDesktopEntry(r.ConfigHome, autostartSubdir, &DesktopEntry{
+			ID: id, Type: sys.Type, Name: name, Hidden: true,
+		})
+		return err
+	}
+
+	home, err := r.ConfigHome()
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(filepath.Join(home, autostartSubdir, id))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// IsAutostartEnabled checks whether an application with the given desktop file
+// ID (e.g. "org.example.App.desktop") starts automatically when the user logs in.
+//
+// The entry in the "autostart" subdirectory of [ConfigHome] takes precedence over
+// entries with the same ID in [ConfigDirs], in order. The application is enabled
+// if the first entry found isn't hidden, and its OnlyShowIn and NotShowIn keys
+// match the current desktop environments, which are listed in $XDG_CURRENT_DESKTOP.
+//
+// Note: this function doesn't check the entry's TryExec key.
+func IsAutostartEnabled(id string) (bool, error) {
+	return defaultResolver.IsAutostartEnabled(id)
+}
+
+// IsAutostartEnabled is like the package-level [IsAutostartEnabled], but uses r's environment.
+func (r *Resolver) IsAutostartEnabled(id string) (bool, error) {
+	if err := r.checkHost(); err != nil {
+		return false, err
+	}
+	if err := checkDesktopFileID(id); err != nil {
+		return false, err
+	}
+
+	paths, err := searchPaths(r.ConfigHome, r.ConfigDirs)
+	if err != nil {
+		return false, err
+	}
+
+	e, err := findAutostartEntry(paths, id)
+	if err != nil || e == nil || e.Hidden {
+		return false, err
+	}
+
+	return e.showIn(r.currentDesktops()), nil
+}
+
+// findAutostartEntry returns the first autostart entry with the given ID in
+// the "autostart" subdirectory of the given paths, or nil if it isn't found.
+func findAutostartEntry(paths []string, id string) (*DesktopEntry, error) {
+	for _, path := range paths {
+		path = filepath.Join(path, autostartSubdir, id)
+		if info, err := os.Stat(path); err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+
+		e, err := readDesktopEntry(path, "")
+		if err != nil {
+			return nil, err
+		}
+		e.ID = id
+		return e, nil
+	}
+
+	return nil, nil
+}
+
+// showIn checks whether the entry's OnlyShowIn and NotShowIn keys allow
+// it to be shown in (or autostarted by) any of the given desktop environments.
+func (e *DesktopEntry) showIn(desktops []string) bool {
+	if len(e.OnlyShowIn) > 0 {
+		return slices.ContainsFunc(desktops, func(d string) bool {
+			return slices.Contains(e.OnlyShowIn, d)
+		})
+	}
+
+	return !slices.ContainsFunc(desktops, func(d string) bool {
+		return slices.Contains(e.NotShowIn, d)
+	})
+}
+
+// currentDesktops returns the names of the current desktop environments,
+// according to the colon-separated list in $XDG_CURRENT_DESKTOP.
+func (r *Resolver) currentDesktops() []string {
+	var desktops []string
+	for d := range strings.SplitSeq(r.getenv("XDG_CURRENT_DESKTOP"), ":") {
+		if d != "" {
+			desktops = append(desktops, d)
+		}
+	}
+	return desktops
+}
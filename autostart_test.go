@@ -0,0 +1,151 @@
+package xdg
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAutostart(t *testing.T) {
+	dir := t.TempDir()
+	home := filepath.Join(dir, "home")
+	sys := filepath.Join(dir, "sys")
+	env := map[string]string{
+		"XDG_CONFIG_HOME":     home,
+		"XDG_CONFIG_DIRS":     sys,
+		"XDG_CURRENT_DESKTOP": "ubuntu:GNOME",
+	}
+	r := &Resolver{Getenv: mapEnv(env)}
+
+	id := "org.example.Agent.desktop"
+	enabled := func() bool {
+		t.Helper()
+		ok, err := r.IsAutostartEnabled(id)
+		if err != nil {
+			t.Fatalf("IsAutostartEnabled() error = %v", err)
+		}
+		return ok
+	}
+
+	if enabled() {
+		t.Error("IsAutostartEnabled() before EnableAutostart() = true, want false")
+	}
+
+	path, err := r.EnableAutostart(&DesktopEntry{ID: id, Name: "Agent", Exec: "agent --background", Hidden: true})
+	if err != nil {
+		t.Fatalf("EnableAutostart() error = %v", err)
+	}
+	if want := filepath.Join(home, "autostart", id); path != want {
+		t.Errorf("EnableAutostart() = %q, want %q", path, want)
+	}
+	if !enabled() {
+		t.Error("IsAutostartEnabled() after EnableAutostart() = false, want true")
+	}
+
+	if err := r.DisableAutostart(id); err != nil {
+		t.Fatalf("DisableAutostart() error = %v", err)
+	}
+	if _, err := os.Stat(path); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("DisableAutostart() didn't remove %q: %v", path, err)
+	}
+	if enabled() {
+		t.Error("IsAutostartEnabled() after DisableAutostart() = true, want false")
+	}
+	if err := r.DisableAutostart(id); err != nil {
+		t.Errorf("DisableAutostart() again error = %v", err)
+	}
+
+	// A system entry is overridden with a hidden user entry.
+	sysPath := filepath.Join(sys, "autostart", id)
+	if err := os.MkdirAll(filepath.Dir(sysPath), NewDirectoryPermissions); err != nil {
+		t.Fatal(err)
+	}
+	content := "[Desktop Entry]\nType=Application\nName=System Agent\nExec=agent\n"
+	if err := os.WriteFile(sysPath, []byte(content), NewFilePermissions); err != nil {
+		t.Fatal(err)
+	}
+	if !enabled() {
+		t.Error("IsAutostartEnabled() with system entry = false, want true")
+	}
+
+	if err := r.DisableAutostart(id); err != nil {
+		t.Fatalf("DisableAutostart() error = %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("DisableAutostart() didn't override system entry: %v", err)
+	}
+	if !strings.Contains(string(data), "Hidden=true\n") {
+		t.Errorf("DisableAutostart() override = %q, want Hidden=true", data)
+	}
+	if enabled() {
+		t.Error("IsAutostartEnabled() with hidden override = true, want false")
+	}
+
+	kdeOnly := &DesktopEntry{ID: id, Name: "Agent", Exec: "agent", OnlyShowIn: []string{"KDE"}}
+	if _, err := r.EnableAutostart(kdeOnly); err != nil {
+		t.Fatalf("EnableAutostart() error = %v", err)
+	}
+	if enabled() {
+		t.Error("IsAutostartEnabled() with OnlyShowIn=KDE = true, want false")
+	}
+	env["XDG_CURRENT_DESKTOP"] = "KDE"
+	if !enabled() {
+		t.Error("IsAutostartEnabled() in KDE with OnlyShowIn=KDE = false, want true")
+	}
+
+	if _, err := r.IsAutostartEnabled("../" + id); err == nil {
+		t.Error("IsAutostartEnabled() with invalid ID error = nil, want error")
+	}
+}
+
+func TestDesktopEntryShowIn(t *testing.T) {
+	tests := []struct {
+		name     string
+		entry    DesktopEntry
+		desktops []string
+		want     bool
+	}{
+		{
+			name: "no_restrictions",
+			want: true,
+		},
+		{
+			name:     "only_show_in_match",
+			entry:    DesktopEntry{OnlyShowIn: []string{"KDE", "GNOME"}},
+			desktops: []string{"ubuntu", "GNOME"},
+			want:     true,
+		},
+		{
+			name:     "only_show_in_mismatch",
+			entry:    DesktopEntry{OnlyShowIn: []string{"KDE"}},
+			desktops: []string{"GNOME"},
+		},
+		{
+			name:  "only_show_in_unknown_desktop",
+			entry: DesktopEntry{OnlyShowIn: []string{"KDE"}},
+		},
+		{
+			name:     "not_show_in_match",
+			entry:    DesktopEntry{NotShowIn: []string{"GNOME"}},
+			desktops: []string{"ubuntu", "GNOME"},
+		},
+		{
+			name:     "not_show_in_mismatch",
+			entry:    DesktopEntry{NotShowIn: []string{"KDE"}},
+			desktops: []string{"GNOME"},
+			want:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.entry.showIn(tt.desktops); got != tt.want {
+				t.Errorf("showIn() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,218 @@
+package xdg
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	blobsSubdir      = "blobs"
+	blobDigestPrefix = "sha256:"
+)
+
+// ErrCorruptBlob is returned when reading a blob from a [BlobStore]
+// whose content doesn't match its digest. The blob is removed.
+var ErrCorruptBlob = errors.New("blob content does not match its digest")
+
+// BlobStore is a content-addressed store of immutable blobs in an app's cache
+// directory, which was opened by [OpenBlobStore]. Blobs are identified by
+// the SHA-256 digests of their content, in the form "sha256:<hex>".
+//
+// It's safe to use a store concurrently, even in different processes.
+// Blobs may be evicted at any time by [BlobStore.Trim] or [TrimCache],
+// so callers should treat missing blobs as cache misses.
+type BlobStore struct {
+	// Path is the full path of the store's directory: "<CacheHome>/<app>/blobs".
+	Path string
+
+	dirType func() (string, error)
+	appName string
+}
+
+// OpenBlobStore returns the content-addressed [BlobStore] of the given app,
+// under [CacheHome]. It creates the store's directory if it doesn't exist yet.
+//
+// Note: this function normalizes the app name and ensures it doesn't
+// contain path elements, but the caller is responsible for input vetting.
+func OpenBlobStore(appName string) (*BlobStore, error) {
+	return defaultResolver.OpenBlobStore(appName)
+}
+
+// OpenBlobStore is like the package-level [OpenBlobStore], but uses r's environment.
+func (r *Resolver) OpenBlobStore(appName string) (*BlobStore, error) {
+	if err := r.checkHost(); err != nil {
+		return nil, err
+	}
+	return openBlobStore(r.CacheHome, appName)
+}
+
+func openBlobStore(dirType func() (string, error), appName string) (*BlobStore, error) {
+	path, err := CreateSubdir(dirType, appName, blobsSubdir)
+	if err != nil {
+		return nil, err
+	}
+	return &BlobStore{Path: path, dirType: dirType, appName: filepath.Clean(appName)}, nil
+}
+
+// Put reads all the data from r, stores it as a blob (unless it's already
+// stored), and returns its digest. The blob becomes visible atomically,
+// only after its entire content is written to stable storage.
+func (s *BlobStore) Put(r io.Reader) (string, error) {
+	root, err := os.OpenRoot(s.Path)
+	if err != nil {
+		return "", err
+	}
+	defer root.Close()
+
+	tempName := "." + rand.Text() + ".tmp"
+	f, err := root.OpenFile(tempName, os.O_WRONLY|os.O_CREATE|os.O_EXCL, NewFilePermissions)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = root.Remove(tempName) }() // No-op after a successful rename.
+
+	h := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(f, h), r); err != nil {
+		_ = f.Close()
+		return "", err
+	}
+	if err := f.Sync(); err != nil {
+		_ = f.Close()
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		return "", err
+	}
+
+	digest := blobDigestPrefix + hex.EncodeToString(h.Sum(nil))
+	name := blobName(digest)
+
+	// Deduplicate, and mark the existing blob as recently used.
+	if _, err := root.Lstat(name); err == nil {
+		now := time.Now()
+		return digest, root.Chtimes(name, now, now)
+	}
+
+	// Override the effect of the process's umask.
+	if err := root.Chmod(tempName, NewFilePermissions); err != nil {
+		return "", err
+	}
+	if err := root.MkdirAll(filepath.Dir(name), NewDirectoryPermissions); err != nil {
+		return "", err
+	}
+	if err := root.Rename(tempName, name); err != nil {
+		return "", err
+	}
+
+	return digest, nil
+}
+
+// Get opens the blob with the given digest for reading. If the blob doesn't
+// exist, it returns an error that wraps [fs.ErrNotExist]. The content is
+// verified while it's read: if it doesn't match the digest, the last read
+// returns [ErrCorruptBlob] instead of [io.EOF], and the blob is removed.
+func (s *BlobStore) Get(digest string) (io.ReadCloser, error) {
+	if err := checkBlobDigest(digest); err != nil {
+		return nil, err
+	}
+
+	path := filepath.Join(s.Path, blobName(digest))
+	f, err := os.Open(path) //gosec:disable G304
+	if err != nil {
+		return nil, err
+	}
+
+	// Mark the blob as recently used, for eviction purposes.
+	now := time.Now()
+	_ = os.Chtimes(path, now, now)
+
+	return &blobReader{f: f, h: sha256.New(), digest: digest, path: path}, nil
+}
+
+// Has checks whether the blob with the given digest exists, without verifying it.
+func (s *BlobStore) Has(digest string) (bool, error) {
+	if err := checkBlobDigest(digest); err != nil {
+		return false, err
+	}
+
+	_, err := os.Lstat(filepath.Join(s.Path, blobName(digest)))
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.Is(err, fs.ErrNotExist):
+		return false, nil
+	default:
+		return false, err
+	}
+}
+
+// Trim enforces a size quota and a maximum age in the store, by removing
+// the least recently used blobs first, like [TrimCache]. Reading or
+// re-inserting a blob marks it as recently used, regardless of
+// [CachePolicy.UseAccessTime].
+func (s *BlobStore) Trim(ctx context.Context, p CachePolicy) (*CacheReport, error) {
+	return trimCacheDir(ctx, s.dirType, s.appName, blobsSubdir, p)
+}
+
+// blobName returns the relative path of a blob in the store, which is
+// sharded by the first 2 hex digits of the digest: "sha256/ab/abcdef...".
+func blobName(digest string) string {
+	sum := strings.TrimPrefix(digest, blobDigestPrefix)
+	return filepath.Join("sha256", sum[:2], sum)
+}
+
+// checkBlobDigest ensures that a digest is well-formed, so it can be used
+// safely as a file path. Hex digits must be in lowercase, like [BlobStore.Put].
+func checkBlobDigest(digest string) error {
+	sum, ok := strings.CutPrefix(digest, blobDigestPrefix)
+	if !ok {
+		return fmt.Errorf("unsupported blob digest %q", digest)
+	}
+	if len(sum) != sha256.Size*2 || strings.Trim(sum, "0123456789abcdef") != "" {
+		return fmt.Errorf("invalid blob digest %q", digest)
+	}
+	return nil
+}
+
+// blobReader verifies the content of a blob while it's read.
+type blobReader struct {
+	f      *os.File
+	h      hash.Hash
+	digest string
+	path   string
+	err    error
+}
+
+func (b *blobReader) Read(p []byte) (int, error) {
+	if b.err != nil {
+		return 0, b.err
+	}
+
+	n, err := b.f.Read(p)
+	b.h.Write(p[:n])
+
+	if errors.Is(err, io.EOF) && blobDigestPrefix+hex.EncodeToString(b.h.Sum(nil)) != b.digest {
+		_ = os.Remove(b.path)
+		err = fmt.Errorf("%w: %q", ErrCorruptBlob, b.path)
+	}
+	if err != nil {
+		b.err = err
+	}
+
+	return n, err
+}
+
+func (b *blobReader) Close() error {
+	return b.f.Close()
+}
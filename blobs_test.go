@@ -0,0 +1,184 @@
+package xdg
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBlobStore(t *testing.T) {
+	s, err := openBlobStore(dirForTest(t.TempDir(), nil), "my_app")
+	if err != nil {
+		t.Fatalf("openBlobStore() error = %v", err)
+	}
+
+	const want = "sha256:2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+	digest, err := s.Put(strings.NewReader("hello"))
+	if err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if digest != want {
+		t.Errorf("Put() = %q, want %q", digest, want)
+	}
+
+	path := filepath.Join(s.Path, "sha256", "2c", strings.TrimPrefix(want, blobDigestPrefix))
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("Put() did not shard the blob: %v", err)
+	}
+
+	// Idempotent insertion.
+	if digest, err := s.Put(strings.NewReader("hello")); err != nil || digest != want {
+		t.Errorf("second Put() = %q, %v, want %q", digest, err, want)
+	}
+
+	if ok, err := s.Has(digest); err != nil || !ok {
+		t.Errorf("Has() = %v, %v, want true", ok, err)
+	}
+
+	r, err := s.Get(digest)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	got, err := io.ReadAll(r)
+	if closeErr := r.Close(); closeErr != nil {
+		t.Errorf("Get() close error = %v", closeErr)
+	}
+	if err != nil {
+		t.Fatalf("Get() read error = %v", err)
+	}
+	if string(got) != "hello" {
+		t.Errorf("Get() content = %q, want %q", got, "hello")
+	}
+
+	missing := "sha256:" + strings.Repeat("0", 64)
+	if ok, err := s.Has(missing); err != nil || ok {
+		t.Errorf("Has() missing blob = %v, %v, want false", ok, err)
+	}
+	if _, err := s.Get(missing); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Get() missing blob error = %v, want %v", err, fs.ErrNotExist)
+	}
+
+	entries, err := os.ReadDir(s.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("Put() left temporary files: %v", entries)
+	}
+}
+
+func TestBlobStoreCorrupt(t *testing.T) {
+	s, err := openBlobStore(dirForTest(t.TempDir(), nil), "my_app")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	digest, err := s.Put(strings.NewReader("hello"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(s.Path, blobName(digest))
+	if err := os.WriteFile(path, []byte("jello"), NewFilePermissions); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := s.Get(digest)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	_, err = io.ReadAll(r)
+	if closeErr := r.Close(); closeErr != nil {
+		t.Errorf("Get() close error = %v", closeErr)
+	}
+	if !errors.Is(err, ErrCorruptBlob) {
+		t.Errorf("Get() read error = %v, want %v", err, ErrCorruptBlob)
+	}
+	if ok, _ := s.Has(digest); ok {
+		t.Error("Get() did not remove corrupt blob")
+	}
+}
+
+func TestBlobStoreTrim(t *testing.T) {
+	s, err := openBlobStore(dirForTest(t.TempDir(), nil), "my_app")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	old, err := s.Put(strings.NewReader("old"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	past := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(filepath.Join(s.Path, blobName(old)), past, past); err != nil {
+		t.Fatal(err)
+	}
+	newer, err := s.Put(strings.NewReader("new"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := s.Trim(t.Context(), CachePolicy{MaxSize: 3})
+	if err != nil {
+		t.Fatalf("Trim() error = %v", err)
+	}
+	if len(report.Removed) != 1 {
+		t.Errorf("Trim() removed %d blobs, want 1", len(report.Removed))
+	}
+	if ok, _ := s.Has(old); ok {
+		t.Error("Trim() did not remove the least recently used blob")
+	}
+	if ok, _ := s.Has(newer); !ok {
+		t.Error("Trim() removed the most recently used blob")
+	}
+}
+
+func TestCheckBlobDigest(t *testing.T) {
+	tests := []struct {
+		name    string
+		digest  string
+		wantErr bool
+	}{
+		{
+			name:   "valid",
+			digest: "sha256:" + strings.Repeat("a1", 32),
+		},
+		{
+			name:    "no_algorithm",
+			digest:  strings.Repeat("a1", 32),
+			wantErr: true,
+		},
+		{
+			name:    "unsupported_algorithm",
+			digest:  "md5:" + strings.Repeat("a1", 16),
+			wantErr: true,
+		},
+		{
+			name:    "too_short",
+			digest:  "sha256:a1",
+			wantErr: true,
+		},
+		{
+			name:    "uppercase",
+			digest:  "sha256:" + strings.Repeat("A1", 32),
+			wantErr: true,
+		},
+		{
+			name:    "path_traversal",
+			digest:  "sha256:../" + strings.Repeat("a", 61),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := checkBlobDigest(tt.digest); (err != nil) != tt.wantErr {
+				t.Errorf("checkBlobDigest() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
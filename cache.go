@@ -0,0 +1,244 @@
+package xdg
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	trimLockName = ".trim"
+
+	// metadataFilePrefix is reserved for the names of files that
+	// this package manages, e.g. by [OpenVersionedCache].
+	metadataFilePrefix = ".xdg-"
+
+	// tempFileGracePeriod protects temporary files which are still being
+	// written by other processes (see [WriteFile]) from being evicted.
+	tempFileGracePeriod = time.Hour
+)
+
+// CachePolicy defines the limits that [TrimCache] enforces.
+// Zero values mean no limit.
+type CachePolicy struct {
+	// MaxSize is the maximum total size of the cache's files, in bytes.
+	MaxSize int64
+	// MaxAge is the maximum time since each file was last used.
+	MaxAge time.Duration
+	// UseAccessTime determines whether the last use of a file is its
+	// access time, instead of its modification time. Note that access times
+	// are not updated by filesystems which are mounted with "noatime", and are
+	// updated only periodically with "relatime", which is the default in Linux.
+	// In operating systems which don't expose access times, this is ignored.
+	UseAccessTime bool
+}
+
+// CacheReport describes the result of [TrimCache].
+type CacheReport struct {
+	// Removed lists the files that were evicted, from the least recently used.
+	Removed []EvictedFile
+	// RemovedSize is the total size of the evicted files, in bytes.
+	RemovedSize int64
+	// RemainingFiles is the number of evictable files that remain in the cache.
+	RemainingFiles int
+	// RemainingSize is the total size of the remaining evictable files, in bytes.
+	RemainingSize int64
+}
+
+// EvictedFile is a file that [TrimCache] removed.
+type EvictedFile struct {
+	Path     string
+	Size     int64
+	LastUsed time.Time
+}
+
+// TrimCache enforces a size quota and a maximum age in the given app's directory
+// under [CacheHome], by removing the least recently used files first. It doesn't
+// remove directories, symbolic links, lock files (see [LockFile]), metadata
+// files of this package, exclusion markers (see [WithExclusionMarkers]),
+// and recent temporary files of [WriteFile], which may still be in progress.
+//
+// Concurrent calls, even in different processes, are serialized with an
+// exclusive [FileLock]. Other processes may still use the cache while it's
+// being trimmed, so they should treat missing files as cache misses. Files
+// that are used after they're scanned but before they're removed are skipped.
+//
+// This function waits until the lock is acquired, or the context is done,
+// and stops removing files when the context is done. If some files can't
+// be removed, it continues, and returns a report along with an error.
+//
+// Note: this function normalizes the app name and ensures it doesn't
+// contain path elements, but the caller is responsible for input vetting.
+func TrimCache(ctx context.Context, appName string, p CachePolicy) (*CacheReport, error) {
+	return defaultResolver.TrimCache(ctx, appName, p)
+}
+
+// TrimCache is like the package-level [TrimCache], but uses r's environment.
+func (r *Resolver) TrimCache(ctx context.Context, appName string, p CachePolicy) (*CacheReport, error) {
+	if err := r.checkHost(); err != nil {
+		return nil, err
+	}
+	return trimCacheDir(ctx, r.CacheHome, appName, "", p)
+}
+
+// trimCacheDir implements [TrimCache] in a subdirectory of an app's directory.
+func trimCacheDir(
+	ctx context.Context,
+	dirType func() (string, error),
+	appName, subpath string,
+	p CachePolicy,
+) (*CacheReport, error) {
+	dir, err := CreateSubdir(dirType, appName, subpath)
+	if err != nil {
+		return nil, err
+	}
+
+	l, err := LockFile(ctx, dirType, appName, trimLockName, true)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = l.Unlock() }()
+
+	root, err := os.OpenRoot(dir)
+	if err != nil {
+		return nil, err
+	}
+	defer root.Close()
+
+	now := time.Now()
+	files, err := scanCacheFiles(root, p.UseAccessTime, now)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &CacheReport{RemainingFiles: len(files)}
+	for _, f := range files {
+		report.RemainingSize += f.Size
+	}
+
+	var errs []error
+	for _, f := range files {
+		expired := p.MaxAge > 0 && now.Sub(f.LastUsed) > p.MaxAge
+		oversized := p.MaxSize > 0 && report.RemainingSize > p.MaxSize
+		if !expired && !oversized {
+			break // The rest of the files were used more recently.
+		}
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, err)
+			break
+		}
+
+		removed, err := removeCacheFile(root, f, p.UseAccessTime)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if !removed {
+			continue
+		}
+
+		f.Path = filepath.Join(dir, f.Path)
+		report.Removed = append(report.Removed, f)
+		report.RemovedSize += f.Size
+		report.RemainingFiles--
+		report.RemainingSize -= f.Size
+	}
+
+	return report, errors.Join(errs...)
+}
+
+// scanCacheFiles returns all the evictable files in the given cache directory,
+// with paths relative to it, sorted by their last use (least recent first).
+func scanCacheFiles(root *os.Root, useAccessTime bool, now time.Time) ([]EvictedFile, error) {
+	var files []EvictedFile
+	err := fs.WalkDir(root.FS(), ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil // Removed by another process during the scan.
+			}
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if !evictable(d.Name(), info, now) {
+			return nil
+		}
+
+		files = append(files, EvictedFile{
+			Path:     filepath.FromSlash(path),
+			Size:     info.Size(),
+			LastUsed: lastUsed(info, useAccessTime),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortStableFunc(files, func(a, b EvictedFile) int {
+		return a.LastUsed.Compare(b.LastUsed)
+	})
+	return files, nil
+}
+
+// evictable checks whether a file may be removed by [TrimCache].
+func evictable(name string, info fs.FileInfo, now time.Time) bool {
+	if strings.HasSuffix(name, lockFileSuffix) || strings.HasPrefix(name, metadataFilePrefix) ||
+		isExclusionMarker(name) {
+		return false
+	}
+
+	if strings.HasPrefix(name, ".") && strings.Contains(name, ".tmp") {
+		return now.Sub(info.ModTime()) > tempFileGracePeriod
+	}
+
+	return true
+}
+
+// removeCacheFile removes a file, unless it was used (or removed)
+// by another process since it was scanned by [scanCacheFiles].
+func removeCacheFile(root *os.Root, f EvictedFile, useAccessTime bool) (bool, error) {
+	info, err := root.Lstat(f.Path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return false, nil
+		}
+		return false, err
+	}
+	if info.Size() != f.Size || !lastUsed(info, useAccessTime).Equal(f.LastUsed) {
+		return false, nil
+	}
+
+	if err := root.Remove(f.Path); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+// lastUsed returns the access or modification time of a file.
+func lastUsed(info fs.FileInfo, useAccessTime bool) time.Time {
+	if useAccessTime {
+		if t, ok := accessTime(info); ok {
+			return t
+		}
+	}
+	return info.ModTime()
+}
@@ -0,0 +1,138 @@
+package xdg
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestTrimCache(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name        string
+		policy      CachePolicy
+		wantRemoved []string
+	}{
+		{
+			name: "no_limits",
+		},
+		{
+			name:        "max_size",
+			policy:      CachePolicy{MaxSize: 250},
+			wantRemoved: []string{"sub/older"},
+		},
+		{
+			name:        "max_age",
+			policy:      CachePolicy{MaxAge: 90 * time.Minute},
+			wantRemoved: []string{"old", "sub/older"},
+		},
+		{
+			name:        "max_age_and_size",
+			policy:      CachePolicy{MaxSize: 50, MaxAge: 150 * time.Minute},
+			wantRemoved: []string{"new", "old", "sub/older"},
+		},
+		{
+			name:   "quota_not_exceeded",
+			policy: CachePolicy{MaxSize: 400, MaxAge: 24 * time.Hour},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			appDir := filepath.Join(dir, "my_app")
+			files := []struct {
+				path string
+				age  time.Duration
+			}{
+				{"new", time.Minute},
+				{"old", 2 * time.Hour},
+				{"sub/older", 3 * time.Hour},
+				{"exempt" + lockFileSuffix, 4 * time.Hour},
+				{".new.tmp123", 5 * time.Minute},
+			}
+			for _, f := range files {
+				path := filepath.Join(appDir, filepath.FromSlash(f.path))
+				if err := os.MkdirAll(filepath.Dir(path), NewDirectoryPermissions); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, make([]byte, 100), NewFilePermissions); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.Chtimes(path, now.Add(-f.age), now.Add(-f.age)); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			got, err := trimCacheDir(t.Context(), dirForTest(dir, nil), "my_app", "", tt.policy)
+			if err != nil {
+				t.Fatalf("trimCacheDir() error = %v", err)
+			}
+
+			var removed []string
+			for _, f := range got.Removed {
+				rel, err := filepath.Rel(appDir, f.Path)
+				if err != nil {
+					t.Fatal(err)
+				}
+				removed = append(removed, filepath.ToSlash(rel))
+			}
+			slices.Sort(removed)
+			if !slices.Equal(removed, tt.wantRemoved) {
+				t.Errorf("trimCacheDir() removed = %q, want %q", removed, tt.wantRemoved)
+			}
+
+			wantRemaining := 3 - len(tt.wantRemoved) // Excluding non-evictable files.
+			if got.RemainingFiles != wantRemaining {
+				t.Errorf("trimCacheDir() RemainingFiles = %d, want %d", got.RemainingFiles, wantRemaining)
+			}
+			if got.RemovedSize != int64(100*len(tt.wantRemoved)) {
+				t.Errorf("trimCacheDir() RemovedSize = %d, want %d", got.RemovedSize, 100*len(tt.wantRemoved))
+			}
+			if got.RemainingSize != int64(100*wantRemaining) {
+				t.Errorf("trimCacheDir() RemainingSize = %d, want %d", got.RemainingSize, 100*wantRemaining)
+			}
+
+			for _, f := range files {
+				_, err := os.Stat(filepath.Join(appDir, filepath.FromSlash(f.path)))
+				if want := slices.Contains(tt.wantRemoved, f.path); os.IsNotExist(err) != want {
+					t.Errorf("file %q removed = %v, want %v", f.path, os.IsNotExist(err), want)
+				}
+			}
+		})
+	}
+}
+
+func TestTrimCacheLocked(t *testing.T) {
+	dir := t.TempDir()
+	l, err := TryLockFile(dirForTest(dir, nil), "my_app", trimLockName, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := l.Unlock(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+	defer cancel()
+
+	if _, err := trimCacheDir(ctx, dirForTest(dir, nil), "my_app", "", CachePolicy{MaxSize: 1}); err == nil {
+		t.Error("trimCacheDir() error = nil, want context deadline error")
+	}
+}
+
+func TestTrimCacheNonHost(t *testing.T) {
+	r := &Resolver{Platform: PlatformWindows, Home: `C:\Users\me`}
+	if hostPlatform == PlatformWindows {
+		r.Platform = PlatformUnix
+	}
+
+	if _, err := r.TrimCache(t.Context(), "my_app", CachePolicy{}); err == nil {
+		t.Error("TrimCache() error = nil, want error")
+	}
+}
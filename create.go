@@ -2,6 +2,7 @@ package xdg
 
 import (
 	"errors"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
@@ -20,28 +21,47 @@ const (
 // CreateDir returns the path to the given app's directory under the given
 // XDG base directory. It creates any directories that don't exist yet.
 //
+// Options such as [WithExclusionMarkers] apply only if
+// this function creates the app's directory.
+//
 // Note: this function normalizes the app name and ensures it does not
 // contain path elements, but the caller is responsible for input vetting.
-func CreateDir(dirType func() (string, error), appName string) (string, error) {
+func CreateDir(dirType func() (string, error), appName string, opts ...CreateOption) (string, error) {
+	path, _, err := createDir(dirType, appName, newCreateOptions(opts))
+	return path, err
+}
+
+// createDir implements [CreateDir], and also reports
+// whether it created the app's directory.
+func createDir(dirType func() (string, error), appName string, o createOptions) (string, bool, error) {
 	appName = filepath.Clean(appName)
 	if appName == "." {
-		return "", errors.New("app name is empty")
+		return "", false, errors.New("app name is empty")
 	}
 	if strings.Contains(appName, pathSep) {
-		return "", errors.New("app name must not contain separator")
+		return "", false, errors.New("app name must not contain separator")
 	}
 
 	path, err := dirType()
 	if err != nil {
-		return "", err
+		return "", false, err
 	}
 
 	path = filepath.Join(path, appName)
+	_, err = os.Stat(path)
+	created := errors.Is(err, fs.ErrNotExist)
+
 	if err := os.MkdirAll(path, NewDirectoryPermissions); err != nil {
-		return "", err
+		return "", false, err
+	}
+
+	if created {
+		if err := o.writeMarkers(path); err != nil {
+			return "", false, err
+		}
 	}
 
-	return path, nil
+	return path, created, nil
 }
 
 // CreateSubdir returns the path to the given subdirectory under the given app's directory,
@@ -52,8 +72,13 @@ func CreateDir(dirType func() (string, error), appName string) (string, error) {
 //
 // Note 2: the subpath parameter may contain 0 or more path elements.
 // This function ensures that it does not escape the app's directory.
-func CreateSubdir(dirType func() (string, error), appName, subpath string) (string, error) {
-	path, err := CreateDir(dirType, appName)
+//
+// Note 3: options such as [WithExclusionMarkers] apply to the topmost
+// directory that this function creates (the app's directory or the
+// subdirectory), if any. Subdirectories inherit their exclusion.
+func CreateSubdir(dirType func() (string, error), appName, subpath string, opts ...CreateOption) (string, error) {
+	o := newCreateOptions(opts)
+	path, created, err := createDir(dirType, appName, o)
 	if err != nil {
 		return "", err
 	}
@@ -69,13 +94,37 @@ func CreateSubdir(dirType func() (string, error), appName, subpath string) (stri
 	}
 	defer root.Close()
 
+	top := ""
+	if !created && len(o.markers) > 0 {
+		top = topmostMissingDir(root, subpath)
+	}
+
 	if err := root.MkdirAll(subpath, NewDirectoryPermissions); err != nil {
 		return "", err
 	}
 
+	if top != "" {
+		if err := o.writeMarkers(filepath.Join(path, top)); err != nil {
+			return "", err
+		}
+	}
+
 	return filepath.Join(path, subpath), nil
 }
 
+// topmostMissingDir returns the shortest prefix of the given
+// relative path that doesn't exist yet, or "" if it exists.
+func topmostMissingDir(root *os.Root, subpath string) string {
+	prefix := ""
+	for elem := range strings.SplitSeq(subpath, pathSep) {
+		prefix = filepath.Join(prefix, elem)
+		if _, err := root.Stat(prefix); errors.Is(err, fs.ErrNotExist) {
+			return prefix
+		}
+	}
+	return ""
+}
+
 // CreateFile returns the path to the given app's file under the given XDG base directory.
 // It creates the file and any parent directories if they don't exist yet.
 //
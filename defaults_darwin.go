@@ -2,37 +2,16 @@ package xdg
 
 import (
 	"path/filepath"
-	"strings"
 )
 
-func defaultCacheHome() string {
-	return filepath.Join(HomeDir(), "Library/Caches")
-}
-
-func defaultConfigHome() string {
-	return filepath.Join(HomeDir(), ".config")
-}
-
-func defaultConfigDirs() string {
-	return strings.Join([]string{
-		filepath.Join(HomeDir(), "Library/Application Support"),
-		"/Library/Application Support",
-		"/etc/xdg",
-	}, listSeparator)
-}
-
-func defaultDataHome() string {
-	return filepath.Join(HomeDir(), "Library/Application Support")
-}
+const hostPlatform = PlatformDarwin
 
-func defaultDataDirs() string {
-	return strings.Join([]string{
-		"/Library/Application Support",
-		filepath.Join(HomeDir(), ".local/share"),
-		"/usr/local/share:/usr/share",
-	}, listSeparator)
+func hostDefaults() Defaults {
+	return darwinDefaults(filepath.Join)
 }
 
-func defaultStateHome() string {
-	return filepath.Join(HomeDir(), "Library/Application Support")
+// hostKnownFolder always returns an empty string, because
+// Known Folders are supported only in Windows.
+func hostKnownFolder(_ string) string {
+	return ""
 }
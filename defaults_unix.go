@@ -6,26 +6,14 @@ import (
 	"path/filepath"
 )
 
-func defaultCacheHome() string {
-	return filepath.Join(HomeDir(), ".cache")
-}
-
-func defaultConfigHome() string {
-	return filepath.Join(HomeDir(), ".config")
-}
-
-func defaultConfigDirs() string {
-	return "/etc/xdg"
-}
-
-func defaultDataHome() string {
-	return filepath.Join(HomeDir(), ".local/share")
-}
+const hostPlatform = PlatformUnix
 
-func defaultDataDirs() string {
-	return "/usr/local/share:/usr/share"
+func hostDefaults() Defaults {
+	return unixDefaults(filepath.Join)
 }
 
-func defaultStateHome() string {
-	return filepath.Join(HomeDir(), ".local/state")
+// hostKnownFolder always returns an empty string, because
+// Known Folders are supported only in Windows.
+func hostKnownFolder(_ string) string {
+	return ""
 }
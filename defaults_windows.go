@@ -7,28 +7,52 @@ import (
 	"golang.org/x/sys/windows"
 )
 
-func defaultCacheHome() string {
-	return filepath.Join(localAppData(), "Cache")
-}
+const hostPlatform = PlatformWindows
 
-func defaultConfigHome() string {
-	return roamingAppData()
+func hostDefaults() Defaults {
+	return windowsDefaults(filepath.Join, hostKnownFolder)
 }
 
-func defaultConfigDirs() string {
-	return programData()
+// hostKnownFolders maps Known Folder names (without the "FOLDERID_" prefix)
+// to their IDs, and to environment variables that may contain their paths.
+var hostKnownFolders = map[string]struct {
+	id         *windows.KNOWNFOLDERID
+	envVarName string
+}{
+	"LocalAppData":   {windows.FOLDERID_LocalAppData, "LOCALAPPDATA"},
+	"RoamingAppData": {windows.FOLDERID_RoamingAppData, "APPDATA"},
+	"ProgramData":    {windows.FOLDERID_ProgramData, "ALLUSERSPROFILE"},
+	"Desktop":        {windows.FOLDERID_Desktop, ""},
+	"Documents":      {windows.FOLDERID_Documents, ""},
+	"Downloads":      {windows.FOLDERID_Downloads, ""},
+	"Music":          {windows.FOLDERID_Music, ""},
+	"Pictures":       {windows.FOLDERID_Pictures, ""},
+	"Public":         {windows.FOLDERID_Public, "PUBLIC"},
+	"Templates":      {windows.FOLDERID_Templates, ""},
+	"Videos":         {windows.FOLDERID_Videos, ""},
 }
 
-func defaultDataHome() string {
-	return localAppData()
-}
+// hostKnownFolder returns the path of a specific Known Folder, by its name
+// (without the "FOLDERID_" prefix). It returns an empty string if all attempts
+// have failed, in which case the caller should construct a default speculative path.
+func hostKnownFolder(name string) string {
+	folder, ok := hostKnownFolders[name]
+	if !ok {
+		return ""
+	}
 
-func defaultDataDirs() string {
-	return programData()
-}
+	if path := folderPath(folder.id, folder.envVarName); path != "" {
+		return path
+	}
+
+	if name == "ProgramData" {
+		if path := os.ExpandEnv(os.Getenv("ProgramData")); path != "" {
+			return path
+		}
+		return filepath.Join(systemDrive(), "ProgramData")
+	}
 
-func defaultStateHome() string {
-	return localAppData()
+	return ""
 }
 
 // folderPath returns the first non-empty path of a specific Known Folder.
@@ -43,6 +67,9 @@ func folderPath(id *windows.KNOWNFOLDERID, envVarName string) string {
 	}
 
 	// Second attempt (less reliable because values can be modified manually).
+	if envVarName == "" {
+		return ""
+	}
 	if path := os.ExpandEnv(os.Getenv(envVarName)); path != "" {
 		return path
 	}
@@ -50,32 +77,6 @@ func folderPath(id *windows.KNOWNFOLDERID, envVarName string) string {
 	return ""
 }
 
-func localAppData() string {
-	if path := folderPath(windows.FOLDERID_LocalAppData, "LOCALAPPDATA"); path != "" {
-		return path
-	}
-	return filepath.Join(HomeDir(), "AppData", "Local")
-}
-
-func roamingAppData() string {
-	if path := folderPath(windows.FOLDERID_RoamingAppData, "APPDATA"); path != "" {
-		return path
-	}
-	return filepath.Join(HomeDir(), "AppData", "Roaming")
-}
-
-func programData() string {
-	if path := folderPath(windows.FOLDERID_ProgramData, "ALLUSERSPROFILE"); path != "" {
-		return path
-	}
-
-	if path := os.ExpandEnv(os.Getenv("ProgramData")); path != "" {
-		return path
-	}
-
-	return filepath.Join(systemDrive(), "ProgramData")
-}
-
 func systemDrive() string {
 	if path := os.Getenv("SystemDrive"); path != "" {
 		return path
@@ -0,0 +1,473 @@
+package xdg
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	desktopEntryGroup   = "Desktop Entry"
+	desktopEntrySuffix  = ".desktop"
+	applicationsSubdir  = "applications"
+	desktopTypeApp      = "Application"
+	desktopExecQuoted   = "\"`$\\"
+	desktopExecReserved = " \t\n\"'\\><~|&;$*?#()`"
+)
+
+// DesktopEntry is a parsed desktop entry file, according to the FreeDesktop.org
+// Desktop Entry specification (https://specifications.freedesktop.org/desktop-entry-spec/).
+// Localized values are resolved according to the locale that was used to parse it.
+type DesktopEntry struct {
+	// ID is the desktop file ID, e.g. "org.example.App.desktop", which is
+	// the file's path relative to its "applications" directory, with "/"
+	// replaced by "-". It's empty if the entry wasn't found by ID.
+	ID string
+	// Path is the full path of the desktop file, if it was read from a file.
+	Path string
+
+	Type        string
+	Name        string
+	GenericName string
+	Comment     string
+	Icon        string
+	Exec        string
+	TryExec     string
+	// WorkingDir is the value of the "Path" key.
+	WorkingDir string
+	Terminal   bool
+	NoDisplay  bool
+	Hidden     bool
+	OnlyShowIn []string
+	NotShowIn  []string
+	MimeType   []string
+	Categories []string
+	Keywords   []string
+
+	group  *keyGroup
+	locale string
+}
+
+// FindDesktopEntry looks for a desktop file with the given ID (e.g. "foo-bar.desktop")
+// in the "applications" subdirectory of [DataHome] and then [DataDirs], and parses it
+// with the locale of the current environment (based on $LC_ALL, $LC_MESSAGES or $LANG).
+// IDs are mapped to subdirectories at dashes, e.g. "foo-bar.desktop" may also be
+// "foo/bar.desktop". If the file isn't found, this function returns nil but no error.
+func FindDesktopEntry(id string) (*DesktopEntry, error) {
+	return defaultResolver.FindDesktopEntry(id)
+}
+
+// FindDesktopEntry is like the package-level [FindDesktopEntry], but uses r's environment.
+func (r *Resolver) FindDesktopEntry(id string) (*DesktopEntry, error) {
+	if err := r.checkHost(); err != nil {
+		return nil, err
+	}
+	if err := checkDesktopFileID(id); err != nil {
+		return nil, err
+	}
+
+	paths, err := searchPaths(r.DataHome, r.DataDirs)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, path := range paths {
+		found := findDesktopFileByID(filepath.Join(path, applicationsSubdir), id)
+		if found == "" {
+			continue
+		}
+
+		e, err := readDesktopEntry(found, r.locale())
+		if err != nil {
+			return nil, err
+		}
+		e.ID = id
+		return e, nil
+	}
+
+	return nil, nil
+}
+
+// WriteDesktopEntry writes a desktop entry file for an application to the
+// "applications" subdirectory of [DataHome], atomically, and returns its path.
+// The file name is the entry's ID, which must end with ".desktop" and must not
+// contain path separators. Localized and unknown keys are not written.
+// Use [QuoteExecArgs] to construct a valid Exec value.
+func WriteDesktopEntry(e *DesktopEntry) (string, error) {
+	return defaultResolver.WriteDesktopEntry(e)
+}
+
+// WriteDesktopEntry is like the package-level [WriteDesktopEntry], but uses r's environment.
+func (r *Resolver) WriteDesktopEntry(e *DesktopEntry) (string, error) {
+	if err := r.checkHost(); err != nil {
+		return "", err
+	}
+	return writeDesktopEntry(r.DataHome, applicationsSubdir, e)
+}
+
+// writeDesktopEntry writes a desktop entry file to the given
+// subdirectory (e.g. "applications") of an XDG base directory.
+func writeDesktopEntry(dirType func() (string, error), subdir string, e *DesktopEntry) (string, error) {
+	if err := checkDesktopFileID(e.ID); err != nil {
+		return "", err
+	}
+
+	data, err := e.marshal()
+	if err != nil {
+		return "", err
+	}
+
+	return WriteFile(dirType, subdir, e.ID, data)
+}
+
+// ParseDesktopEntry parses the content of a desktop entry file. The locale
+// (e.g. "de_DE.UTF-8") determines the values of localized keys. If it's
+// empty, only unlocalized values are used.
+func ParseDesktopEntry(data []byte, locale string) (*DesktopEntry, error) {
+	kf, err := parseKeyFile(data)
+	if err != nil {
+		return nil, err
+	}
+
+	g := kf.group(desktopEntryGroup)
+	if g == nil {
+		return nil, fmt.Errorf("missing [%s] group", desktopEntryGroup)
+	}
+
+	str := func(key string) string {
+		v, _ := g.value(key)
+		return unescapeValue(v)
+	}
+	localeStr := func(key string) string {
+		v, _ := g.localeValue(key, locale)
+		return unescapeValue(v)
+	}
+	boolean := func(key string) bool {
+		v, _ := g.value(key)
+		return v == "true"
+	}
+	list := func(key string) []string {
+		v, _ := g.value(key)
+		return splitList(v)
+	}
+
+	keywords, _ := g.localeValue("Keywords", locale)
+	return &DesktopEntry{
+		Type:        str("Type"),
+		Name:        localeStr("Name"),
+		GenericName: localeStr("GenericName"),
+		Comment:     localeStr("Comment"),
+		Icon:        localeStr("Icon"),
+		Exec:        str("Exec"),
+		TryExec:     str("TryExec"),
+		WorkingDir:  str("Path"),
+		Terminal:    boolean("Terminal"),
+		NoDisplay:   boolean("NoDisplay"),
+		Hidden:      boolean("Hidden"),
+		OnlyShowIn:  list("OnlyShowIn"),
+		NotShowIn:   list("NotShowIn"),
+		MimeType:    list("MimeType"),
+		Categories:  list("Categories"),
+		Keywords:    splitList(keywords),
+		group:       g,
+		locale:      locale,
+	}, nil
+}
+
+// Value returns the unescaped value of any key in the "[Desktop Entry]" group,
+// including keys without a dedicated field (e.g. "StartupWMClass", or "X-" keys),
+// and whether it exists. Localized keys are resolved like the entry's fields.
+func (e *DesktopEntry) Value(key string) (string, bool) {
+	v, ok := e.group.localeValue(key, e.locale)
+	return unescapeValue(v), ok
+}
+
+// ExpandExec splits the entry's Exec key into a command-line argument list,
+// and expands its field codes with the given files or URLs:
+//
+//   - %f and %u expand to the first file or URL (the application should be launched
+//     separately for each one), and %F and %U expand to all of them,
+//   - %i expands to "--icon" and the entry's Icon, if it's not empty,
+//   - %c expands to the entry's (localized) Name,
+//   - %k expands to the entry's Path,
+//   - %% expands to "%",
+//   - Deprecated field codes (%d, %D, %n, %N, %v, %m) are removed.
+func (e *DesktopEntry) ExpandExec(files ...string) ([]string, error) {
+	args, err := splitExec(e.Exec)
+	if err != nil {
+		return nil, err
+	}
+
+	var argv []string
+	for _, a := range args {
+		if a.quoted {
+			argv = append(argv, a.value)
+			continue
+		}
+
+		switch a.value {
+		case "%F", "%U":
+			argv = append(argv, files...)
+			continue
+		case "%f", "%u":
+			if len(files) > 0 {
+				argv = append(argv, files[0])
+			}
+			continue
+		case "%i":
+			if e.Icon != "" {
+				argv = append(argv, "--icon", e.Icon)
+			}
+			continue
+		}
+
+		expanded, err := e.expandFieldCodes(a.value, files)
+		if err != nil {
+			return nil, err
+		}
+		if expanded != "" {
+			argv = append(argv, expanded)
+		}
+	}
+
+	if len(argv) == 0 {
+		return nil, errors.New("desktop entry has an empty Exec key")
+	}
+	return argv, nil
+}
+
+// expandFieldCodes expands the field codes inside a single unquoted argument.
+func (e *DesktopEntry) expandFieldCodes(arg string, files []string) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(arg); i++ {
+		if arg[i] != '%' {
+			b.WriteByte(arg[i])
+			continue
+		}
+		if i == len(arg)-1 {
+			return "", fmt.Errorf("incomplete field code in Exec argument %q", arg)
+		}
+
+		i++
+		switch arg[i] {
+		case '%':
+			b.WriteByte('%')
+		case 'f', 'u':
+			if len(files) > 0 {
+				b.WriteString(files[0])
+			}
+		case 'c':
+			b.WriteString(e.Name)
+		case 'k':
+			b.WriteString(e.Path)
+		case 'd', 'D', 'n', 'N', 'v', 'm':
+			// Deprecated.
+		default:
+			return "", fmt.Errorf("invalid field code %%%c in Exec argument %q", arg[i], arg)
+		}
+	}
+	return b.String(), nil
+}
+
+type execArg struct {
+	value  string
+	quoted bool
+}
+
+// splitExec splits the (unescaped) value of an Exec key into arguments,
+// which may be quoted with double quotes. Inside quotes, the characters
+// '"', '`', '$' and '\' must be escaped with a backslash.
+func splitExec(exec string) ([]execArg, error) {
+	var args []execArg
+	var b strings.Builder
+	inArg, inQuotes, quoted := false, false, false
+
+	for i := 0; i < len(exec); i++ {
+		c := exec[i]
+		switch {
+		case inQuotes && c == '\\' && i < len(exec)-1 && strings.IndexByte(desktopExecQuoted, exec[i+1]) >= 0:
+			i++
+			b.WriteByte(exec[i])
+		case inQuotes && c == '"':
+			inQuotes = false
+		case inQuotes:
+			b.WriteByte(c)
+		case c == '"':
+			inArg, inQuotes, quoted = true, true, true
+		case c == ' ' || c == '\t' || c == '\n':
+			if inArg {
+				args = append(args, execArg{value: b.String(), quoted: quoted})
+				b.Reset()
+				inArg, quoted = false, false
+			}
+		default:
+			inArg = true
+			b.WriteByte(c)
+		}
+	}
+
+	if inQuotes {
+		return nil, fmt.Errorf("unterminated quote in Exec key %q", exec)
+	}
+	if inArg {
+		args = append(args, execArg{value: b.String(), quoted: quoted})
+	}
+	return args, nil
+}
+
+// QuoteExecArgs returns the value of an Exec key for the given command-line
+// arguments, which are quoted if necessary. Note that field codes (e.g. "%f")
+// must not be quoted, so they should be appended to the result separately.
+func QuoteExecArgs(args ...string) string {
+	quoted := make([]string, len(args))
+	for i, a := range args {
+		quoted[i] = quoteExecArg(a)
+	}
+	return strings.Join(quoted, " ")
+}
+
+// quoteExecArg quotes a command-line argument for an Exec key, if necessary.
+func quoteExecArg(arg string) string {
+	if arg != "" && !strings.ContainsAny(arg, desktopExecReserved) {
+		return arg
+	}
+
+	var b strings.Builder
+	b.WriteByte('"')
+	for i := range len(arg) {
+		if strings.IndexByte(desktopExecQuoted, arg[i]) >= 0 {
+			b.WriteByte('\\')
+		}
+		b.WriteByte(arg[i])
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// marshal returns the content of a desktop entry file, with
+// all the non-empty fields, in the "[Desktop Entry]" group.
+func (e *DesktopEntry) marshal() ([]byte, error) {
+	if e.Name == "" {
+		return nil, errors.New("desktop entry must have a name")
+	}
+
+	kf := &keyFile{}
+	g := kf.addGroup(desktopEntryGroup)
+
+	typ := e.Type
+	if typ == "" {
+		typ = desktopTypeApp
+	}
+	g.set("Type", escapeValue(typ))
+
+	for _, kv := range []struct{ key, value string }{
+		{"Name", e.Name},
+		{"GenericName", e.GenericName},
+		{"Comment", e.Comment},
+		{"Icon", e.Icon},
+		{"Exec", e.Exec},
+		{"TryExec", e.TryExec},
+		{"Path", e.WorkingDir},
+	} {
+		if kv.value != "" {
+			g.set(kv.key, escapeValue(kv.value))
+		}
+	}
+
+	for _, kv := range []struct {
+		key   string
+		value bool
+	}{
+		{"Terminal", e.Terminal},
+		{"NoDisplay", e.NoDisplay},
+		{"Hidden", e.Hidden},
+	} {
+		if kv.value {
+			g.set(kv.key, "true")
+		}
+	}
+
+	for _, kv := range []struct {
+		key   string
+		value []string
+	}{
+		{"OnlyShowIn", e.OnlyShowIn},
+		{"NotShowIn", e.NotShowIn},
+		{"MimeType", e.MimeType},
+		{"Categories", e.Categories},
+		{"Keywords", e.Keywords},
+	} {
+		if len(kv.value) > 0 {
+			g.set(kv.key, joinList(kv.value))
+		}
+	}
+
+	return kf.bytes(), nil
+}
+
+// readDesktopEntry reads and parses a desktop entry file.
+func readDesktopEntry(path, locale string) (*DesktopEntry, error) {
+	data, err := os.ReadFile(path) //gosec:disable G304
+	if err != nil {
+		return nil, err
+	}
+
+	e, err := ParseDesktopEntry(data, locale)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse desktop entry %q: %w", path, err)
+	}
+
+	e.Path = path
+	return e, nil
+}
+
+// findDesktopFileByID looks for a desktop file with the given ID in the given
+// directory, where each "-" in the ID may also be a subdirectory separator.
+// It returns the full path of the file, or an empty string if it isn't found.
+func findDesktopFileByID(dir, id string) string {
+	path := filepath.Join(dir, id)
+	if info, err := os.Stat(path); err == nil && info.Mode().IsRegular() {
+		return path
+	}
+
+	for i := range len(id) {
+		if id[i] != '-' || id[:i] == "." || id[:i] == ".." {
+			continue
+		}
+
+		subdir := filepath.Join(dir, id[:i])
+		if info, err := os.Stat(subdir); err == nil && info.IsDir() {
+			if path := findDesktopFileByID(subdir, id[i+1:]); path != "" {
+				return path
+			}
+		}
+	}
+
+	return ""
+}
+
+// checkDesktopFileID ensures that a desktop file ID is a
+// valid file name, which can't escape its directory.
+func checkDesktopFileID(id string) error {
+	name, ok := strings.CutSuffix(id, desktopEntrySuffix)
+	switch {
+	case !ok || name == "":
+		return fmt.Errorf("desktop file ID must end with %q: %q", desktopEntrySuffix, id)
+	case strings.ContainsAny(id, `/\`) || strings.HasPrefix(id, "."):
+		return fmt.Errorf("invalid desktop file ID %q", id)
+	default:
+		return nil
+	}
+}
+
+// locale returns the locale of the current environment for messages.
+func (r *Resolver) locale() string {
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if v := r.getenv(name); v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,337 @@
+package xdg
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+const testDesktopEntry = `[Desktop Entry]
+Type=Application
+Name=Text Editor
+Name[de]=Texteditor
+Comment=Edit\stext files\nquickly
+Icon=editor
+Exec=editor --new-window %U
+Terminal=false
+MimeType=text/plain;text/x-c\;v2;
+Categories=Utility;TextEditor
+Keywords=text;edit;
+Keywords[de]=Text;Bearbeiten;
+StartupWMClass=Editor
+X-Custom[de]=Wert
+
+[Desktop Action new]
+Name=New Window
+`
+
+func TestParseDesktopEntry(t *testing.T) {
+	e, err := ParseDesktopEntry([]byte(testDesktopEntry), "de_DE.UTF-8")
+	if err != nil {
+		t.Fatalf("ParseDesktopEntry() error = %v", err)
+	}
+
+	if e.Type != "Application" {
+		t.Errorf("Type = %q, want %q", e.Type, "Application")
+	}
+	if e.Name != "Texteditor" {
+		t.Errorf("Name = %q, want %q", e.Name, "Texteditor")
+	}
+	if e.Comment != "Edit text files\nquickly" {
+		t.Errorf("Comment = %q, want %q", e.Comment, "Edit text files\nquickly")
+	}
+	if e.Terminal {
+		t.Error("Terminal = true, want false")
+	}
+	if want := []string{"text/plain", "text/x-c;v2"}; !slices.Equal(e.MimeType, want) {
+		t.Errorf("MimeType = %q, want %q", e.MimeType, want)
+	}
+	if want := []string{"Utility", "TextEditor"}; !slices.Equal(e.Categories, want) {
+		t.Errorf("Categories = %q, want %q", e.Categories, want)
+	}
+	if want := []string{"Text", "Bearbeiten"}; !slices.Equal(e.Keywords, want) {
+		t.Errorf("Keywords = %q, want %q", e.Keywords, want)
+	}
+
+	if v, ok := e.Value("StartupWMClass"); !ok || v != "Editor" {
+		t.Errorf("Value(StartupWMClass) = %q, %v, want %q", v, ok, "Editor")
+	}
+	if v, ok := e.Value("X-Custom"); !ok || v != "Wert" {
+		t.Errorf("Value(X-Custom) = %q, %v, want %q", v, ok, "Wert")
+	}
+	if _, ok := e.Value("Missing"); ok {
+		t.Error("Value(Missing) = true, want false")
+	}
+
+	e, err = ParseDesktopEntry([]byte(testDesktopEntry), "")
+	if err != nil {
+		t.Fatalf("ParseDesktopEntry() error = %v", err)
+	}
+	if e.Name != "Text Editor" {
+		t.Errorf("Name = %q, want %q", e.Name, "Text Editor")
+	}
+
+	if _, err := ParseDesktopEntry([]byte("[Other]\nName=x\n"), ""); err == nil {
+		t.Error("ParseDesktopEntry() without [Desktop Entry] error = nil, want error")
+	}
+}
+
+func TestDesktopEntryExpandExec(t *testing.T) {
+	tests := []struct {
+		name    string
+		exec    string
+		files   []string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:  "multiple_files",
+			exec:  "editor --new %F",
+			files: []string{"a.txt", "b c.txt"},
+			want:  []string{"editor", "--new", "a.txt", "b c.txt"},
+		},
+		{
+			name:  "single_file",
+			exec:  "editor %f",
+			files: []string{"a.txt", "b.txt"},
+			want:  []string{"editor", "a.txt"},
+		},
+		{
+			name: "no_files",
+			exec: "editor %u",
+			want: []string{"editor"},
+		},
+		{
+			name:  "embedded_field_code",
+			exec:  "editor --file=%f --name=%c 100%%",
+			files: []string{"a.txt"},
+			want:  []string{"editor", "--file=a.txt", "--name=Editor", "100%"},
+		},
+		{
+			name: "icon_and_location",
+			exec: "editor %i %k",
+			want: []string{"editor", "--icon", "editor-icon", "/apps/editor.desktop"},
+		},
+		{
+			name: "deprecated_field_codes",
+			exec: "editor %d %m",
+			want: []string{"editor"},
+		},
+		{
+			name:  "quoted",
+			exec:  `"/opt/my editor/bin" "say \"hi\" \$HOME \\ %f" %f`,
+			files: []string{"a.txt"},
+			want:  []string{"/opt/my editor/bin", `say "hi" $HOME \ %f`, "a.txt"},
+		},
+		{
+			name:    "unterminated_quote",
+			exec:    `"editor`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid_field_code",
+			exec:    "editor %x",
+			wantErr: true,
+		},
+		{
+			name:    "incomplete_field_code",
+			exec:    "editor 100%",
+			wantErr: true,
+		},
+		{
+			name:    "empty",
+			exec:    " ",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &DesktopEntry{Name: "Editor", Icon: "editor-icon", Path: "/apps/editor.desktop", Exec: tt.exec}
+			got, err := e.ExpandExec(tt.files...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ExpandExec() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("ExpandExec() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQuoteExecArgs(t *testing.T) {
+	args := []string{"/opt/my editor/bin", "--flag", "", `say "hi" $HOME \`, "a;b"}
+	quoted := QuoteExecArgs(args...)
+
+	want := `"/opt/my editor/bin" --flag "" "say \"hi\" \$HOME \\" "a;b"`
+	if quoted != want {
+		t.Errorf("QuoteExecArgs() = %q, want %q", quoted, want)
+	}
+
+	e := &DesktopEntry{Exec: quoted + " %F"}
+	got, err := e.ExpandExec("file")
+	if err != nil {
+		t.Fatalf("ExpandExec() error = %v", err)
+	}
+	if want := append(args, "file"); !slices.Equal(got, want) {
+		t.Errorf("ExpandExec() = %q, want %q", got, want)
+	}
+}
+
+func TestFindDesktopEntry(t *testing.T) {
+	dir := t.TempDir()
+	home := filepath.Join(dir, "home")
+	sys := filepath.Join(dir, "sys")
+	r := &Resolver{Getenv: mapEnv(map[string]string{
+		"XDG_DATA_HOME": home,
+		"XDG_DATA_DIRS": sys,
+		"LANG":          "de_DE.UTF-8",
+	})}
+
+	files := map[string]string{
+		filepath.Join(home, "applications", "org.example.Home.desktop"):  "Home",
+		filepath.Join(sys, "applications", "org.example.Home.desktop"):   "Shadowed",
+		filepath.Join(sys, "applications", "kde", "org.example.desktop"): "Subdir",
+		filepath.Join(sys, "applications", "foo-bar", "baz-qux.desktop"): "Nested",
+		filepath.Join(sys, "applications", "kde-org.example.desktop"):    "Sibling",
+		filepath.Join(sys, "applications", "broken.desktop"):             "",
+	}
+	for path, name := range files {
+		content := "[Desktop Entry]\nType=Application\nName=" + name + "\nName[de]=" + name + " (de)\n"
+		if name == "" {
+			content = "Name=broken\n"
+		}
+		if err := os.MkdirAll(filepath.Dir(path), NewDirectoryPermissions); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), NewFilePermissions); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		id       string
+		wantName string
+		wantPath string
+		wantErr  bool
+	}{
+		{
+			name:     "data_home_first",
+			id:       "org.example.Home.desktop",
+			wantName: "Home (de)",
+			wantPath: filepath.Join(home, "applications", "org.example.Home.desktop"),
+		},
+		{
+			name:     "exact_name_first",
+			id:       "kde-org.example.desktop",
+			wantName: "Sibling (de)",
+			wantPath: filepath.Join(sys, "applications", "kde-org.example.desktop"),
+		},
+		{
+			name:     "nested_subdirs",
+			id:       "foo-bar-baz-qux.desktop",
+			wantName: "Nested (de)",
+			wantPath: filepath.Join(sys, "applications", "foo-bar", "baz-qux.desktop"),
+		},
+		{
+			name: "not_found",
+			id:   "missing.desktop",
+		},
+		{
+			name:    "invalid_file",
+			id:      "broken.desktop",
+			wantErr: true,
+		},
+		{
+			name:    "invalid_id",
+			id:      "../org.example.Home.desktop",
+			wantErr: true,
+		},
+		{
+			name:    "missing_suffix",
+			id:      "org.example.Home",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := r.FindDesktopEntry(tt.id)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FindDesktopEntry() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantPath == "" {
+				if e != nil {
+					t.Errorf("FindDesktopEntry() = %v, want nil", e)
+				}
+				return
+			}
+
+			if e.ID != tt.id {
+				t.Errorf("FindDesktopEntry() ID = %q, want %q", e.ID, tt.id)
+			}
+			if e.Path != tt.wantPath {
+				t.Errorf("FindDesktopEntry() Path = %q, want %q", e.Path, tt.wantPath)
+			}
+			if e.Name != tt.wantName {
+				t.Errorf("FindDesktopEntry() Name = %q, want %q", e.Name, tt.wantName)
+			}
+		})
+	}
+}
+
+func TestWriteDesktopEntry(t *testing.T) {
+	dir := t.TempDir()
+	r := &Resolver{Getenv: mapEnv(map[string]string{"XDG_DATA_HOME": dir})}
+
+	e := &DesktopEntry{
+		ID:         "org.example.App.desktop",
+		Name:       " Example; App",
+		Comment:    "Line 1\nLine 2",
+		Exec:       QuoteExecArgs("/opt/example app/bin") + " %U",
+		Terminal:   true,
+		MimeType:   []string{"text/plain", "x-scheme-handler/a;b"},
+		Categories: []string{"Utility"},
+	}
+
+	path, err := r.WriteDesktopEntry(e)
+	if err != nil {
+		t.Fatalf("WriteDesktopEntry() error = %v", err)
+	}
+	if want := filepath.Join(dir, "applications", e.ID); path != want {
+		t.Errorf("WriteDesktopEntry() = %q, want %q", path, want)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(data), "[Desktop Entry]\nType=Application\nName=\\sExample; App\n") {
+		t.Errorf("WriteDesktopEntry() content = %q", data)
+	}
+
+	got, err := r.FindDesktopEntry(e.ID)
+	if err != nil {
+		t.Fatalf("FindDesktopEntry() error = %v", err)
+	}
+	if got.Name != e.Name || got.Comment != e.Comment || got.Exec != e.Exec || !got.Terminal {
+		t.Errorf("FindDesktopEntry() = %+v, want %+v", got, e)
+	}
+	if !slices.Equal(got.MimeType, e.MimeType) || !slices.Equal(got.Categories, e.Categories) {
+		t.Errorf("FindDesktopEntry() lists = %q, %q, want %q, %q",
+			got.MimeType, got.Categories, e.MimeType, e.Categories)
+	}
+
+	for _, bad := range []*DesktopEntry{
+		{ID: "org.example.App.desktop"},
+		{ID: "org.example.App", Name: "App"},
+		{ID: "sub/org.example.App.desktop", Name: "App"},
+	} {
+		if _, err := r.WriteDesktopEntry(bad); err == nil {
+			t.Errorf("WriteDesktopEntry(%+v) error = nil, want error", bad)
+		}
+	}
+}
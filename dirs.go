@@ -4,8 +4,6 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"regexp"
-	"runtime"
 	"strings"
 )
 
@@ -24,7 +22,7 @@ const (
 //   - Nested environment variables are auto-expanded,
 //   - Environment variables may end with a trailing slash.
 func CacheHome() (string, error) {
-	return dir("XDG_CACHE_HOME", defaultCacheHome)
+	return defaultResolver.CacheHome()
 }
 
 // ConfigHome returns the absolute path of the base directory
@@ -39,13 +37,13 @@ func CacheHome() (string, error) {
 //   - Environment variables may end with a trailing slash,
 //   - Different (easier and isolated) default value in macOS.
 func ConfigHome() (string, error) {
-	return dir("XDG_CONFIG_HOME", defaultConfigHome)
+	return defaultResolver.ConfigHome()
 }
 
 // ConfigDirs returns a set of preference-ordered base directories relative
 // to which configuration files should be searched, after [ConfigHome].
 func ConfigDirs() ([]string, error) {
-	return dirs("XDG_CONFIG_DIRS", defaultConfigDirs)
+	return defaultResolver.ConfigDirs()
 }
 
 // DataHome returns the absolute path of the base directory
@@ -54,13 +52,13 @@ func ConfigDirs() ([]string, error) {
 // Users should create their own application-specific
 // subdirectory within this one and use that.
 func DataHome() (string, error) {
-	return dir("XDG_DATA_HOME", defaultDataHome)
+	return defaultResolver.DataHome()
 }
 
 // DataDirs returns a set of preference-ordered base directories
 // relative to which data files should be searched, after [DataHome].
 func DataDirs() ([]string, error) {
-	return dirs("XDG_DATA_DIRS", defaultDataDirs)
+	return defaultResolver.DataDirs()
 }
 
 // StateHome returns the absolute path of the base directory
@@ -76,60 +74,79 @@ func DataDirs() ([]string, error) {
 //   - Current state of the application that can be reused on a
 //     restart (view, layout, open files, undo history, ...).
 func StateHome() (string, error) {
-	return dir("XDG_STATE_HOME", defaultStateHome)
+	return defaultResolver.StateHome()
 }
 
-func dir(envVarName string, defaultFunc func() string) (string, error) {
-	path := expand(os.Getenv(envVarName))
+func (r *Resolver) dir(envVarName string, customDefault, platformDefault func(string) string) (string, error) {
+	path := r.expand(r.getenv(envVarName))
 	if path == "" {
-		path = defaultFunc()
+		var err error
+		if path, err = r.defaultPath(customDefault, platformDefault); err != nil {
+			return "", err
+		}
 	}
 
-	if filepath.IsAbs(path) {
+	if r.syntax().isAbs(path) {
 		return path, nil
 	}
 
 	return "", fmt.Errorf("path in %s is relative: %q", envVarName, path)
 }
 
-func dirs(envVarName string, defaultFunc func() string) ([]string, error) {
-	path := os.Getenv(envVarName)
+func (r *Resolver) dirs(envVarName string, customDefault, platformDefault func(string) string) ([]string, error) {
+	path := r.getenv(envVarName)
 	if path == "" {
-		path = defaultFunc()
+		var err error
+		if path, err = r.defaultPath(customDefault, platformDefault); err != nil {
+			return nil, err
+		}
 	}
 
+	syntax := r.syntax()
 	var paths []string
-	for p := range strings.SplitSeq(path, listSeparator) {
-		if absDirExists(p) {
-			paths = append(paths, expand(p))
+	for p := range strings.SplitSeq(path, syntax.listSep) {
+		if syntax.readOnly && syntax.isAbs(p) || absDirExists(p) {
+			paths = append(paths, r.expand(p))
 		}
 	}
 
 	return paths, nil
 }
 
+// expand is like [Resolver.expand], using the default resolver.
+func expand(path string) string {
+	return defaultResolver.expand(path)
+}
+
 // expand expands environment variables in the given path
 // ("$var" and "${var}" in Unix-like operating systems, and
 // "%var%" in Windows), and "~" in Unix-like operating systems.
 // References to undefined variables are replaced by the empty string.
-// This function also calls [filepath.Clean] to clean the path.
-func expand(path string) string {
+// This function also cleans the path, like [filepath.Clean].
+func (r *Resolver) expand(path string) string {
 	if path == "" {
 		return path
 	}
 
-	switch runtime.GOOS {
-	case "windows":
-		// [os.ExpandEnv] doesn't support %var%, only ${var}.
-		path = regexp.MustCompile(`%(\w+?)%`).ReplaceAllString(path, "${$1}")
-	default:
-		// [os.ExpandEnv] doesn't support "~".
-		if len(path) > 0 && path[0] == '~' {
-			path = strings.Replace(path, "~", "${HOME}", 1)
-		}
+	syntax := r.syntax()
+	if syntax.winVars {
+		// [os.Expand] doesn't support %var%, only ${var}.
+		path = windowsVarPattern.ReplaceAllString(path, "${$1}")
+	} else if path[0] == '~' {
+		// [os.Expand] doesn't support "~".
+		path = strings.Replace(path, "~", "${HOME}", 1)
 	}
 
-	return filepath.Clean(os.ExpandEnv(path))
+	return syntax.clean(os.Expand(path, r.expandVar))
+}
+
+// expandVar is like [Resolver.getenv], except that it
+// prefers the resolver's custom home directory, if set.
+func (r *Resolver) expandVar(key string) string {
+	if r.Home != "" && (key == "HOME" || key == "USERPROFILE") {
+		return r.Home
+	}
+	return r.getenv(key)
 }
 
 // absDirExists checks whether the given path is an existing absolute directory.
@@ -9,23 +9,18 @@ import (
 )
 
 func TestCacheHome(t *testing.T) {
-	cachedHomeDir = ""
-	t.Cleanup(func() { cachedHomeDir = "" })
 	t.Setenv("XDG_CACHE_HOME", "")
 
 	got, err := CacheHome()
 	if err != nil {
 		t.Errorf("CacheHome() error = %v", err)
 	}
-	if got != defaultCacheHome() {
-		t.Errorf("CacheHome() = %q, want %q", got, defaultCacheHome())
+	if got != hostDefaults().CacheHome(MustHomeDir()) {
+		t.Errorf("CacheHome() = %q, want %q", got, hostDefaults().CacheHome(MustHomeDir()))
 	}
 }
 
 func TestConfigHome(t *testing.T) {
-	cachedHomeDir = ""
-	t.Cleanup(func() { cachedHomeDir = "" })
-
 	tests := []struct {
 		name    string
 		env     string
@@ -34,7 +29,7 @@ func TestConfigHome(t *testing.T) {
 	}{
 		{
 			name: "empty_env_var",
-			want: defaultConfigHome(),
+			want: hostDefaults().ConfigHome(MustHomeDir()),
 		},
 		{
 			name: "valid_env_var",
@@ -64,9 +59,6 @@ func TestConfigHome(t *testing.T) {
 }
 
 func TestConfigDirs(t *testing.T) {
-	cachedHomeDir = ""
-	t.Cleanup(func() { cachedHomeDir = "" })
-
 	dir := t.TempDir()
 	if err := os.Mkdir(filepath.Join(dir, "dir"), NewDirectoryPermissions); err != nil {
 		t.Fatal(err)
@@ -113,23 +105,18 @@ func TestConfigDirs(t *testing.T) {
 }
 
 func TestDataHome(t *testing.T) {
-	cachedHomeDir = ""
-	t.Cleanup(func() { cachedHomeDir = "" })
 	t.Setenv("XDG_DATA_HOME", "")
 
 	got, err := DataHome()
 	if err != nil {
 		t.Errorf("DataHome() error = %v", err)
 	}
-	if got != defaultDataHome() {
-		t.Errorf("DataHome() = %q, want %q", got, defaultDataHome())
+	if got != hostDefaults().DataHome(MustHomeDir()) {
+		t.Errorf("DataHome() = %q, want %q", got, hostDefaults().DataHome(MustHomeDir()))
 	}
 }
 
 func TestDataDirs(t *testing.T) {
-	cachedHomeDir = ""
-	t.Cleanup(func() { cachedHomeDir = "" })
-
 	dir := t.TempDir()
 	if err := os.Mkdir(filepath.Join(dir, "dir"), NewDirectoryPermissions); err != nil {
 		t.Fatal(err)
@@ -149,16 +136,14 @@ func TestDataDirs(t *testing.T) {
 }
 
 func TestStateHome(t *testing.T) {
-	cachedHomeDir = ""
-	t.Cleanup(func() { cachedHomeDir = "" })
 	t.Setenv("XDG_STATE_HOME", "")
 
 	got, err := StateHome()
 	if err != nil {
 		t.Errorf("StateHome() error = %v", err)
 	}
-	if got != defaultStateHome() {
-		t.Errorf("StateHome() = %q, want %q", got, defaultStateHome())
+	if got != hostDefaults().StateHome(MustHomeDir()) {
+		t.Errorf("StateHome() = %q, want %q", got, hostDefaults().StateHome(MustHomeDir()))
 	}
 }
 
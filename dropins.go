@@ -0,0 +1,203 @@
+package xdg
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// DropIn is a drop-in configuration file, which was found by [FindConfigDropIns].
+type DropIn struct {
+	// Name is the file name, which determines the order of drop-ins.
+	Name string
+	// Path is the full path of the file.
+	Path string
+	// BaseDir is the XDG base directory in which the file was found,
+	// e.g. the path of [ConfigHome], or one of the [ConfigDirs].
+	BaseDir string
+}
+
+// FindConfigDropIns looks for systemd-style drop-in files (e.g. "*.conf") in
+// a drop-in directory (e.g. "conf.d") of an app, in all the [ConfigHome] and
+// [ConfigDirs] directories. It returns them sorted lexically by file name,
+// i.e. in the order in which they should be applied.
+//
+// A file in a directory with higher precedence shadows files with the same
+// name in directories with lower precedence. A file can also mask them, i.e.
+// disable them entirely, if it's empty or a symbolic link to "/dev/null".
+// Masking files are not included in the result.
+//
+// Note 1: this function normalizes the app name and ensures it doesn't
+// contain path elements, but the caller is responsible for input vetting.
+//
+// Note 2: the dirPath parameter may contain 1 or more path elements.
+// This function ensures that it does not escape the app's directory.
+// The pattern syntax is the same as in [filepath.Match].
+func FindConfigDropIns(appName, dirPath, pattern string) ([]DropIn, error) {
+	return defaultResolver.FindConfigDropIns(appName, dirPath, pattern)
+}
+
+// FindConfigDropIns is like the package-level [FindConfigDropIns], but uses r's environment.
+func (r *Resolver) FindConfigDropIns(appName, dirPath, pattern string) ([]DropIn, error) {
+	if err := r.checkHost(); err != nil {
+		return nil, err
+	}
+	return findDropIns(r.ConfigHome, r.ConfigDirs, appName, dirPath, pattern)
+}
+
+func findDropIns(
+	home func() (string, error),
+	dirs func() ([]string, error),
+	appName, dirPath, pattern string,
+) ([]DropIn, error) {
+	appName = filepath.Clean(appName)
+	if appName == "." {
+		return nil, errors.New("app name is empty")
+	}
+	if strings.Contains(appName, pathSep) {
+		return nil, errors.New("app name must not contain separator")
+	}
+
+	dirPath = filepath.Clean(dirPath)
+	if dirPath == "." {
+		return nil, errors.New("drop-in directory path is empty")
+	}
+	if _, err := filepath.Match(pattern, ""); err != nil {
+		return nil, fmt.Errorf("invalid drop-in file name pattern %q: %w", pattern, err)
+	}
+
+	paths, err := searchPaths(home, dirs)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[string]bool{}
+	var dropIns []DropIn
+	for _, path := range paths {
+		found, err := readDropInDir(path, appName, dirPath, pattern)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, d := range found {
+			if seen[d.Name] {
+				continue // Shadowed or masked by a directory with higher precedence.
+			}
+			seen[d.Name] = true
+
+			if d.Path != "" {
+				dropIns = append(dropIns, d)
+			}
+		}
+	}
+
+	slices.SortFunc(dropIns, func(a, b DropIn) int {
+		return cmp.Compare(a.Name, b.Name)
+	})
+
+	return dropIns, nil
+}
+
+// readDropInDir returns the files that match the given pattern in an app's
+// drop-in directory under a specific XDG base directory. Masking files are
+// returned with an empty path. A missing directory is not considered an error.
+func readDropInDir(path, appName, dirPath, pattern string) ([]DropIn, error) {
+	appPath := filepath.Join(path, appName)
+	info, err := os.Stat(appPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, nil // Found app file instead of app directory.
+	}
+
+	root, err := os.OpenRoot(appPath)
+	if err != nil {
+		return nil, err
+	}
+	defer root.Close()
+
+	f, err := root.Open(dirPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	entries, err := f.ReadDir(-1)
+	if err != nil {
+		// Found a file instead of a directory.
+		if info, statErr := f.Stat(); statErr == nil && !info.IsDir() {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var dropIns []DropIn
+	for _, e := range entries {
+		if ok, _ := filepath.Match(pattern, e.Name()); !ok {
+			continue
+		}
+
+		name := filepath.Join(dirPath, e.Name())
+		kind, err := dropInKind(root, name)
+		if err != nil {
+			return nil, err
+		}
+
+		switch kind {
+		case dropInFile:
+			dropIns = append(dropIns, DropIn{Name: e.Name(), Path: filepath.Join(appPath, name), BaseDir: path})
+		case dropInMask:
+			dropIns = append(dropIns, DropIn{Name: e.Name(), BaseDir: path})
+		}
+	}
+
+	return dropIns, nil
+}
+
+const (
+	dropInIgnored = iota // Subdirectory, special file, or broken symbolic link.
+	dropInFile
+	dropInMask // Empty file, or symbolic link to "/dev/null".
+)
+
+// dropInKind checks whether the given drop-in path is a regular file, or whether
+// it masks files with the same name in directories with lower precedence.
+func dropInKind(root *os.Root, name string) (int, error) {
+	if target, err := root.Readlink(name); err == nil && target == os.DevNull {
+		return dropInMask, nil
+	}
+
+	info, err := root.Stat(name)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		// Symbolic links may point outside the root (e.g. to an absolute path).
+		if linfo, lerr := root.Lstat(name); lerr == nil && linfo.Mode()&os.ModeSymlink != 0 {
+			info, err = os.Stat(filepath.Join(root.Name(), name))
+		}
+	}
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return dropInIgnored, nil
+		}
+		return dropInIgnored, err
+	}
+
+	switch {
+	case !info.Mode().IsRegular():
+		return dropInIgnored, nil
+	case info.Size() == 0:
+		return dropInMask, nil
+	default:
+		return dropInFile, nil
+	}
+}
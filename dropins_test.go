@@ -0,0 +1,147 @@
+package xdg
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"testing"
+)
+
+func TestFindDropIns(t *testing.T) {
+	home, sys1, sys2 := t.TempDir(), t.TempDir(), t.TempDir()
+
+	files := []struct {
+		dir, name, content string
+	}{
+		{sys2, "10-base.conf", "base"},
+		{sys2, "20-shadowed.conf", "sys2"},
+		{sys2, "30-masked.conf", "sys2"},
+		{sys2, "ignored.txt", "sys2"},
+		{sys1, "20-shadowed.conf", "sys1"},
+		{sys1, "40-sys1.conf", "sys1"},
+		{home, "20-shadowed.conf", "home"},
+		{home, "30-masked.conf", ""},
+		{home, "05-home.conf", "home"},
+	}
+	for _, f := range files {
+		path := filepath.Join(f.dir, "my_app", "conf.d", f.name)
+		if err := os.MkdirAll(filepath.Dir(path), NewDirectoryPermissions); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(f.content), NewFilePermissions); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := os.Mkdir(filepath.Join(sys1, "my_app", "conf.d", "50-subdir.conf"), NewDirectoryPermissions); err != nil {
+		t.Fatal(err)
+	}
+
+	dropIn := func(dir, name string) DropIn {
+		return DropIn{Name: name, Path: filepath.Join(dir, "my_app", "conf.d", name), BaseDir: dir}
+	}
+	want := []DropIn{
+		dropIn(home, "05-home.conf"),
+		dropIn(sys2, "10-base.conf"),
+		dropIn(home, "20-shadowed.conf"),
+		dropIn(sys1, "40-sys1.conf"),
+	}
+
+	if runtime.GOOS != "windows" {
+		if err := os.Symlink(os.DevNull, filepath.Join(home, "my_app", "conf.d", "40-sys1.conf")); err != nil {
+			t.Fatal(err)
+		}
+		want = want[:3]
+	}
+
+	got, err := findDropIns(dirForTest(home, nil), dirsForTest([]string{sys1, sys2}, nil), "my_app", "conf.d", "*.conf")
+	if err != nil {
+		t.Fatalf("findDropIns() error = %v", err)
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("findDropIns() = %v, want %v", got, want)
+	}
+}
+
+func TestFindDropInsExternalSymlink(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("creating symbolic links requires special privileges in Windows")
+	}
+
+	home, external := t.TempDir(), t.TempDir()
+	dir := filepath.Join(home, "my_app", "conf.d")
+	if err := os.MkdirAll(dir, NewDirectoryPermissions); err != nil {
+		t.Fatal(err)
+	}
+
+	target := filepath.Join(external, "10-a.conf")
+	if err := os.WriteFile(target, []byte("external"), NewFilePermissions); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(target, filepath.Join(dir, "10-a.conf")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(external, "missing.conf"), filepath.Join(dir, "20-broken.conf")); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := findDropIns(dirForTest(home, nil), nil, "my_app", "conf.d", "*.conf")
+	if err != nil {
+		t.Fatalf("findDropIns() error = %v", err)
+	}
+
+	want := []DropIn{{Name: "10-a.conf", Path: filepath.Join(dir, "10-a.conf"), BaseDir: home}}
+	if !slices.Equal(got, want) {
+		t.Errorf("findDropIns() = %v, want %v", got, want)
+	}
+}
+
+func TestFindDropInsErrors(t *testing.T) {
+	home := dirForTest(t.TempDir(), nil)
+
+	tests := []struct {
+		name    string
+		appName string
+		dirPath string
+		pattern string
+	}{
+		{
+			name:    "empty_app_name",
+			dirPath: "conf.d",
+			pattern: "*",
+		},
+		{
+			name:    "insecure_app_name",
+			appName: "../other_app",
+			dirPath: "conf.d",
+			pattern: "*",
+		},
+		{
+			name:    "empty_dir_path",
+			appName: "my_app",
+			pattern: "*",
+		},
+		{
+			name:    "bad_pattern",
+			appName: "my_app",
+			dirPath: "conf.d",
+			pattern: "[",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := findDropIns(home, nil, tt.appName, tt.dirPath, tt.pattern); err == nil {
+				t.Error("findDropIns() error = nil, wantErr true")
+			}
+		})
+	}
+}
+
+func TestFindDropInsMissingDir(t *testing.T) {
+	got, err := findDropIns(dirForTest(t.TempDir(), nil), nil, "my_app", "conf.d", "*")
+	if err != nil || len(got) != 0 {
+		t.Errorf("findDropIns() = %v, %v, want none", got, err)
+	}
+}
@@ -2,6 +2,7 @@ package xdg_test
 
 import (
 	"fmt"
+	"slices"
 
 	"github.com/tzrikka/xdg"
 )
@@ -70,6 +71,17 @@ func ExampleCreateSubdir() {
 	fmt.Printf("Subdirectory under my app's data dir: %s\n", dataPath)
 }
 
+func ExampleWithExclusionMarkers() {
+	// Exclude the app's cache dir from backups, if it's created now.
+	path, err := xdg.CreateDir(xdg.CacheHome, "my_app", xdg.WithExclusionMarkers(xdg.CacheDirTag))
+	if err != nil {
+		fmt.Println(err) // Input or runtime error.
+		return
+	}
+
+	fmt.Printf("My app's cache dir: %s\n", path)
+}
+
 func ExampleCreateFile() {
 	cfgPath, err := xdg.CreateFile(xdg.ConfigHome, "my_app", "config_file")
 	if err != nil {
@@ -136,3 +148,44 @@ func ExampleFindDataFile() {
 
 	fmt.Printf("Found my app's data file in this path: %s\n", path)
 }
+
+func ExampleResolver() {
+	env := map[string]string{
+		"XDG_CONFIG_HOME": "/home/child/.config",
+	}
+	r := &xdg.Resolver{
+		Getenv: func(key string) string { return env[key] },
+		Home:   "/home/child",
+	}
+
+	path, err := r.ConfigHome()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	fmt.Printf("Config home dir for a child process: %s\n", path)
+}
+
+func ExampleFindAllConfigFiles() {
+	files, err := xdg.FindAllConfigFiles("my_app", "config_file")
+	if err != nil {
+		fmt.Println(err) // Input or runtime error.
+		return
+	}
+
+	// Apply the least important layer first, so that more important layers override it.
+	for _, f := range slices.Backward(files) {
+		fmt.Printf("Found my app's config file in %s: %s\n", f.BaseDir, f.Path)
+	}
+}
+
+func ExampleWriteFile() {
+	path, err := xdg.WriteFile(xdg.StateHome, "my_app", "state.json", []byte(`{"open_files": []}`))
+	if err != nil {
+		fmt.Println(err) // Input or runtime error.
+		return
+	}
+
+	fmt.Printf("My app's state file was replaced atomically: %s\n", path)
+}
@@ -2,6 +2,7 @@ package xdg
 
 import (
 	"errors"
+	"iter"
 	"os"
 	"path/filepath"
 	"strings"
@@ -12,7 +13,7 @@ import (
 // it returns an empty string but no error. An error is returned only
 // if the input parameters are invalid, or in case of a runtime error.
 func FindCacheFile(appName, filePath string) (string, error) {
-	return findFile(CacheHome, nil, appName, filePath)
+	return defaultResolver.FindCacheFile(appName, filePath)
 }
 
 // FindConfigFile looks for a file in an app's [ConfigHome] and [ConfigDirs]
@@ -20,7 +21,7 @@ func FindCacheFile(appName, filePath string) (string, error) {
 // If not, it returns an empty string but no error. An error is returned
 // only if the input parameters are invalid, or in case of a runtime error.
 func FindConfigFile(appName, filePath string) (string, error) {
-	return findFile(ConfigHome, ConfigDirs, appName, filePath)
+	return defaultResolver.FindConfigFile(appName, filePath)
 }
 
 // FindDataFile looks for a file in an app's [DataHome] and [DataDirs]
@@ -28,7 +29,7 @@ func FindConfigFile(appName, filePath string) (string, error) {
 // If not, it returns an empty string but no error. An error is returned
 // only if the input parameters are invalid, or in case of a runtime error.
 func FindDataFile(appName, filePath string) (string, error) {
-	return findFile(DataHome, DataDirs, appName, filePath)
+	return defaultResolver.FindDataFile(appName, filePath)
 }
 
 // FindStateFile looks for a file in an app's [StateHome] directory.
@@ -36,50 +37,133 @@ func FindDataFile(appName, filePath string) (string, error) {
 // it returns an empty string but no error. An error is returned only
 // if the input parameters are invalid, or in case of a runtime error.
 func FindStateFile(appName, filePath string) (string, error) {
-	return findFile(StateHome, nil, appName, filePath)
+	return defaultResolver.FindStateFile(appName, filePath)
+}
+
+// FoundFile is a file that was found in an XDG base directory.
+type FoundFile struct {
+	// Path is the full path of the file.
+	Path string
+	// BaseDir is the XDG base directory in which the file was found,
+	// e.g. the path of [ConfigHome], or one of the [ConfigDirs].
+	BaseDir string
+}
+
+// FindAllConfigFiles looks for a file in an app's [ConfigHome] and [ConfigDirs]
+// directories, and returns all the matches in precedence order (i.e. the most
+// important one first). If none is found, this function returns an empty list
+// but no error. An error is returned only if the input parameters are invalid,
+// or in case of a runtime error.
+func FindAllConfigFiles(appName, filePath string) ([]FoundFile, error) {
+	return defaultResolver.FindAllConfigFiles(appName, filePath)
+}
+
+// FindAllDataFiles looks for a file in an app's [DataHome] and [DataDirs]
+// directories, and returns all the matches in precedence order (i.e. the most
+// important one first). If none is found, this function returns an empty list
+// but no error. An error is returned only if the input parameters are invalid,
+// or in case of a runtime error.
+func FindAllDataFiles(appName, filePath string) ([]FoundFile, error) {
+	return defaultResolver.FindAllDataFiles(appName, filePath)
+}
+
+// ConfigFiles is an iterator form of [FindAllConfigFiles]. If there is
+// an error, it's yielded with an empty [FoundFile] and the iteration stops.
+func ConfigFiles(appName, filePath string) iter.Seq2[FoundFile, error] {
+	return defaultResolver.ConfigFiles(appName, filePath)
+}
+
+// DataFiles is an iterator form of [FindAllDataFiles]. If there is
+// an error, it's yielded with an empty [FoundFile] and the iteration stops.
+func DataFiles(appName, filePath string) iter.Seq2[FoundFile, error] {
+	return defaultResolver.DataFiles(appName, filePath)
 }
 
 func findFile(home func() (string, error), dirs func() ([]string, error), appName, filePath string) (string, error) {
-	appName = filepath.Clean(appName)
-	if appName == "." {
-		return "", errors.New("app name is empty")
+	for f, err := range findFiles(home, dirs, appName, filePath) {
+		return f.Path, err
 	}
-	if strings.Contains(appName, pathSep) {
-		return "", errors.New("app name must not contain separator")
+	return "", nil
+}
+
+func findAllFiles(
+	home func() (string, error),
+	dirs func() ([]string, error),
+	appName, filePath string,
+) ([]FoundFile, error) {
+	var files []FoundFile
+	for f, err := range findFiles(home, dirs, appName, filePath) {
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, f)
 	}
+	return files, nil
+}
 
-	filePath = filepath.Clean(filePath)
-	if filePath == "." {
-		return "", errors.New("file path is empty")
+// findFiles yields all the matches of a file in an app's XDG base directories, in
+// precedence order. If there is an error, it yields it and the iteration stops.
+func findFiles(
+	home func() (string, error),
+	dirs func() ([]string, error),
+	appName, filePath string,
+) iter.Seq2[FoundFile, error] {
+	return func(yield func(FoundFile, error) bool) {
+		appName = filepath.Clean(appName)
+		if appName == "." {
+			yield(FoundFile{}, errors.New("app name is empty"))
+			return
+		}
+		if strings.Contains(appName, pathSep) {
+			yield(FoundFile{}, errors.New("app name must not contain separator"))
+			return
+		}
+
+		filePath = filepath.Clean(filePath)
+		if filePath == "." {
+			yield(FoundFile{}, errors.New("file path is empty"))
+			return
+		}
+
+		paths, err := searchPaths(home, dirs)
+		if err != nil {
+			yield(FoundFile{}, err)
+			return
+		}
+
+		for _, path := range paths {
+			fp, err := fullPath(path, appName, filePath)
+			if err != nil {
+				yield(FoundFile{}, err)
+				return
+			}
+			if fp != "" && !yield(FoundFile{Path: fp, BaseDir: path}, nil) {
+				return
+			}
+		}
 	}
+}
 
+// searchPaths returns the XDG base directories in
+// which to search for files, in precedence order.
+func searchPaths(home func() (string, error), dirs func() ([]string, error)) ([]string, error) {
 	firstPath, err := home()
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	var morePaths []string
 	if dirs != nil {
 		morePaths, err = dirs()
 		if err != nil {
-			return "", err
+			return nil, err
 		}
 	}
 
 	paths := make([]string, 1, 1+len(morePaths))
 	paths[0] = firstPath
 
-	for _, path := range append(paths, morePaths...) {
-		fp, err := fullPath(path, appName, filePath)
-		if err != nil {
-			return "", err
-		}
-		if fp != "" {
-			return fp, nil
-		}
-	}
-
-	return "", nil
+	return append(paths, morePaths...), nil
 }
 
 func fullPath(path, appName, filePath string) (string, error) {
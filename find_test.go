@@ -4,6 +4,7 @@ import (
 	"errors"
 	"os"
 	"path/filepath"
+	"slices"
 	"testing"
 )
 
@@ -256,3 +257,71 @@ func TestFullPath(t *testing.T) {
 		})
 	}
 }
+
+func TestFindAllFiles(t *testing.T) {
+	homeDir, dirsDir1, dirsDir2 := t.TempDir(), t.TempDir(), t.TempDir()
+	var want []FoundFile
+	for _, dir := range []string{homeDir, dirsDir2} {
+		path, err := CreateFilePath(dirForTest(dir, nil), "appName", "subdir/file")
+		if err != nil {
+			t.Fatalf("failed to write test file: %v", err)
+		}
+		want = append(want, FoundFile{Path: path, BaseDir: dir})
+	}
+
+	home := dirForTest(homeDir, nil)
+	dirs := dirsForTest([]string{dirsDir1, dirsDir2}, nil)
+
+	got, err := findAllFiles(home, dirs, "appName", "subdir/file")
+	if err != nil {
+		t.Fatalf("findAllFiles() error = %v", err)
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("findAllFiles() = %v, want %v", got, want)
+	}
+
+	got, err = findAllFiles(home, dirs, "appName", "nonexistentFileName")
+	if err != nil || len(got) != 0 {
+		t.Errorf("findAllFiles() = %v, %v, want none", got, err)
+	}
+
+	if _, err := findAllFiles(home, dirsForTest(nil, errors.New("dirs error")), "appName", "file"); err == nil {
+		t.Error("findAllFiles() error = nil, wantErr true")
+	}
+
+	// Iterator form, with early termination.
+	for f, err := range findFiles(home, dirs, "appName", "subdir/file") {
+		if err != nil {
+			t.Fatalf("findFiles() error = %v", err)
+		}
+		if f != want[0] {
+			t.Errorf("findFiles() first = %v, want %v", f, want[0])
+		}
+		break
+	}
+}
+
+func TestFindAllConfigFiles(t *testing.T) {
+	homeDir, dirsDir := t.TempDir(), t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", homeDir)
+	t.Setenv("XDG_CONFIG_DIRS", dirsDir)
+
+	path, err := CreateFile(dirForTest(dirsDir, nil), "my_app", "my_file")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := FindAllConfigFiles("my_app", "my_file")
+	if err != nil {
+		t.Fatalf("FindAllConfigFiles() error = %v", err)
+	}
+	if want := []FoundFile{{Path: path, BaseDir: dirsDir}}; !slices.Equal(got, want) {
+		t.Errorf("FindAllConfigFiles() = %v, want %v", got, want)
+	}
+
+	for _, err := range ConfigFiles("", "my_file") {
+		if err == nil {
+			t.Error("ConfigFiles() error = nil, wantErr true")
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package xdg
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/fs"
+	"iter"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// AppendHistory appends an entry to the given app's history file (e.g. the
+// commands of an interactive shell) under [StateHome]. It creates the file
+// and any parent directories if they don't exist yet.
+//
+// Empty entries, and entries which are identical to the last one, are ignored.
+// Entries may contain any characters, including newlines: each one is stored
+// in a single escaped line. If maxEntries is positive, the oldest entries
+// are removed from the file when it grows beyond that number.
+//
+// Concurrent calls, even in different processes, are serialized with an
+// exclusive lock on a "<file>.lock" file, so they don't lose any entries.
+//
+// Note 1: this function normalizes the app name and ensures it doesn't
+// contain path elements, but the caller is responsible for input vetting.
+//
+// Note 2: the filePath parameter must contain at least a filename, and may contain a prefix of
+// 0 or more path elements. This function ensures that it does not escape the app's directory.
+func AppendHistory(appName, filePath, entry string, maxEntries int) error {
+	return defaultResolver.AppendHistory(appName, filePath, entry, maxEntries)
+}
+
+// AppendHistory is like the package-level [AppendHistory], but uses r's environment.
+func (r *Resolver) AppendHistory(appName, filePath, entry string, maxEntries int) error {
+	if err := r.checkHost(); err != nil {
+		return err
+	}
+	if entry == "" {
+		return nil
+	}
+
+	path, err := CreateFilePath(r.StateHome, appName, filePath)
+	if err != nil {
+		return err
+	}
+
+	lockF, err := os.OpenFile(path+lockFileSuffix, os.O_RDWR|os.O_CREATE, NewFilePermissions) //gosec:disable G304
+	if err != nil {
+		return err
+	}
+	defer lockF.Close()
+
+	if err := waitLockFile(context.Background(), lockF, true); err != nil {
+		return err
+	}
+	defer func() { _ = unlockFile(lockF) }()
+
+	data, err := os.ReadFile(path) //gosec:disable G304
+	if err != nil {
+		return err
+	}
+
+	lines := historyLines(data)
+	line := escapeHistoryEntry(entry)
+	if len(lines) > 0 && lines[len(lines)-1] == line {
+		return nil
+	}
+
+	if maxEntries > 0 && len(lines) >= maxEntries {
+		lines = append(lines[len(lines)-maxEntries+1:], line)
+		dir, file := filepath.Split(path)
+		return writeFileAtomic(dir, file, []byte(strings.Join(lines, "\n")+"\n"), NewFilePermissions)
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, NewFilePermissions) //gosec:disable G304
+	if err != nil {
+		return err
+	}
+
+	// Discard a truncated last line, e.g. after a crash.
+	if len(data) > 0 && data[len(data)-1] != '\n' {
+		if err := f.Truncate(int64(bytes.LastIndexByte(data, '\n') + 1)); err != nil {
+			_ = f.Close()
+			return err
+		}
+	}
+	if _, err := f.WriteString(line + "\n"); err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// LoadHistory returns an iterator over the entries of the given app's history
+// file under [StateHome] (see [AppendHistory]), from the newest to the oldest.
+// If the file doesn't exist, the iterator is empty. A runtime error, if any,
+// is yielded once and ends the iteration.
+//
+// Note: the filePath parameter is normalized and vetted like in [FindStateFile].
+func LoadHistory(appName, filePath string) iter.Seq2[string, error] {
+	return defaultResolver.LoadHistory(appName, filePath)
+}
+
+// LoadHistory is like the package-level [LoadHistory], but uses r's environment.
+func (r *Resolver) LoadHistory(appName, filePath string) iter.Seq2[string, error] {
+	path, err := r.FindStateFile(appName, filePath)
+	if err != nil {
+		return yieldError[string](err)
+	}
+	if path == "" {
+		return func(func(string, error) bool) {}
+	}
+
+	data, err := readHistoryFile(path)
+	if err != nil {
+		return yieldError[string](err)
+	}
+
+	return func(yield func(string, error) bool) {
+		for _, line := range slices.Backward(historyLines(data)) {
+			if !yield(unescapeHistoryEntry(line), nil) {
+				return
+			}
+		}
+	}
+}
+
+// readHistoryFile reads a history file, with a shared lock if
+// its lock file exists, to avoid reading a partial append.
+func readHistoryFile(path string) ([]byte, error) {
+	lockF, err := os.Open(path + lockFileSuffix) //gosec:disable G304
+	switch {
+	case err == nil:
+		defer lockF.Close()
+		if err := waitLockFile(context.Background(), lockF, false); err != nil {
+			return nil, err
+		}
+		defer func() { _ = unlockFile(lockF) }()
+	case !errors.Is(err, fs.ErrNotExist):
+		return nil, err
+	}
+
+	return os.ReadFile(path) //gosec:disable G304
+}
+
+// historyLines splits the content of a history file into lines,
+// ignoring empty lines, and a truncated last line.
+func historyLines(data []byte) []string {
+	if i := bytes.LastIndexByte(data, '\n'); i >= 0 {
+		data = data[:i]
+	} else {
+		data = nil
+	}
+
+	var lines []string
+	for line := range strings.SplitSeq(string(data), "\n") {
+		if line = strings.TrimSuffix(line, "\r"); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+var (
+	historyEscaper   = strings.NewReplacer(`\`, `\\`, "\n", `\n`, "\r", `\r`)
+	historyUnescaper = strings.NewReplacer(`\\`, `\`, `\n`, "\n", `\r`, "\r")
+)
+
+func escapeHistoryEntry(s string) string {
+	return historyEscaper.Replace(s)
+}
+
+func unescapeHistoryEntry(s string) string {
+	return historyUnescaper.Replace(s)
+}
@@ -0,0 +1,132 @@
+package xdg
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestHistory(t *testing.T) {
+	r := &Resolver{Getenv: mapEnv(map[string]string{"XDG_STATE_HOME": t.TempDir()})}
+
+	for _, entry := range []string{"one", "two", "two", "", "multi\nline \\n", "three", "four"} {
+		if err := r.AppendHistory("my_app", "sub/history", entry, 4); err != nil {
+			t.Fatalf("AppendHistory(%q) error = %v", entry, err)
+		}
+	}
+
+	var got []string
+	for entry, err := range r.LoadHistory("my_app", "sub/history") {
+		if err != nil {
+			t.Fatalf("LoadHistory() error = %v", err)
+		}
+		got = append(got, entry)
+	}
+
+	want := []string{"four", "three", "multi\nline \\n", "two"}
+	if !slices.Equal(got, want) {
+		t.Errorf("LoadHistory() = %q, want %q", got, want)
+	}
+}
+
+func TestHistoryConcurrentAppends(t *testing.T) {
+	r := &Resolver{Getenv: mapEnv(map[string]string{"XDG_STATE_HOME": t.TempDir()})}
+
+	const n = 20
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Go(func() {
+			if err := r.AppendHistory("my_app", "history", string(rune('a'+i)), n/2); err != nil {
+				t.Errorf("AppendHistory() error = %v", err)
+			}
+		})
+	}
+	wg.Wait()
+
+	got := 0
+	for _, err := range r.LoadHistory("my_app", "history") {
+		if err != nil {
+			t.Fatalf("LoadHistory() error = %v", err)
+		}
+		got++
+	}
+	if got != n/2 {
+		t.Errorf("LoadHistory() returned %d entries, want %d", got, n/2)
+	}
+}
+
+func TestLoadHistoryMissing(t *testing.T) {
+	r := &Resolver{Getenv: mapEnv(map[string]string{"XDG_STATE_HOME": t.TempDir()})}
+
+	for entry, err := range r.LoadHistory("my_app", "history") {
+		t.Errorf("LoadHistory() yielded %q, %v, want nothing", entry, err)
+	}
+}
+
+func TestHistoryLines(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want []string
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name: "lines",
+			data: "a\nb\r\n\nc\n",
+			want: []string{"a", "b", "c"},
+		},
+		{
+			name: "truncated",
+			data: "a\nb",
+			want: []string{"a"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := historyLines([]byte(tt.data)); !slices.Equal(got, tt.want) {
+				t.Errorf("historyLines() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAppendHistoryTruncated(t *testing.T) {
+	dir := t.TempDir()
+	r := &Resolver{Getenv: mapEnv(map[string]string{"XDG_STATE_HOME": dir})}
+
+	if err := os.MkdirAll(filepath.Join(dir, "my_app"), NewDirectoryPermissions); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "my_app", "history")
+	if err := os.WriteFile(path, []byte("a\npartial"), NewFilePermissions); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.AppendHistory("my_app", "history", "b", 0); err != nil {
+		t.Fatalf("AppendHistory() error = %v", err)
+	}
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "a\nb\n"; string(got) != want {
+		t.Errorf("history file = %q, want %q", got, want)
+	}
+}
+
+func TestAppendHistoryInvalidPath(t *testing.T) {
+	dir := t.TempDir()
+	r := &Resolver{Getenv: mapEnv(map[string]string{"XDG_STATE_HOME": dir})}
+
+	if err := r.AppendHistory("my_app", "hist/", "entry", 0); err == nil {
+		t.Error("AppendHistory() error = nil, want error")
+	}
+	if _, err := os.Lstat(filepath.Join(dir, "my_app", "hist", lockFileSuffix)); !os.IsNotExist(err) {
+		t.Errorf("AppendHistory() created a lock file for an invalid path: %v", err)
+	}
+}
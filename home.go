@@ -1,22 +1,70 @@
 package xdg
 
 import (
+	"fmt"
 	"os"
+	"os/user"
+	"path/filepath"
+	"sync"
 )
 
-var cachedHomeDir string
+var (
+	homeDirMu     sync.Mutex
+	cachedHomeDir string
+)
+
+// HomeDir returns the absolute path of the current user's home directory.
+//
+// It's based on the HOME environment variable in Unix and macOS, and
+// USERPROFILE in Windows (see [os.UserHomeDir]), so changes to them during
+// runtime are honored. If they're not set, this function falls back to
+// the user database, and caches the result (see [ResetHomeDir]).
+//
+// This function returns an error if the home directory is unknown,
+// or if it's a relative path. It's safe for concurrent use.
+func HomeDir() (string, error) {
+	path, err := os.UserHomeDir()
+	if err != nil {
+		if path, err = userHomeDir(); err != nil {
+			return "", err
+		}
+	}
+
+	if !filepath.IsAbs(path) {
+		return "", fmt.Errorf("home directory is relative: %q", path)
+	}
+
+	return path, nil
+}
+
+// ResetHomeDir clears the cached home directory of the current
+// user, which [HomeDir] uses only if the environment variable that
+// specifies it is not set. It's safe for concurrent use.
+func ResetHomeDir() {
+	homeDirMu.Lock()
+	defer homeDirMu.Unlock()
+
+	cachedHomeDir = ""
+}
+
+// userHomeDir returns the current user's home directory from the
+// user database, which is more expensive than an environment variable.
+func userHomeDir() (string, error) {
+	homeDirMu.Lock()
+	defer homeDirMu.Unlock()
 
-// HomeDir returns the current user's home directory.
-// It is assumed that this value does not change during runtime.
-func HomeDir() string {
 	if cachedHomeDir != "" {
-		return cachedHomeDir
+		return cachedHomeDir, nil
 	}
 
-	path, err := os.UserHomeDir()
-	if err == nil {
-		cachedHomeDir = path
+	u, err := user.Current()
+	if err != nil {
+		return "", fmt.Errorf("failed to determine home directory: %w", err)
+	}
+	if u.HomeDir == "" {
+		return "", fmt.Errorf("home directory of user %q is unknown", u.Username)
 	}
 
-	return path
+	cachedHomeDir = u.HomeDir
+	return cachedHomeDir, nil
 }
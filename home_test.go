@@ -1,25 +1,35 @@
 package xdg
 
 import (
+	"path/filepath"
+	"sync"
 	"testing"
 )
 
 func TestHomeDir(t *testing.T) {
-	cachedHomeDir = ""
-	t.Cleanup(func() { cachedHomeDir = "" })
-	want := "TEST1"
+	abs1 := filepath.Join(t.TempDir(), "home1")
+	abs2 := filepath.Join(t.TempDir(), "home2")
 
 	tests := []struct {
-		name string
-		env  string
+		name    string
+		env     string
+		want    string
+		wantErr bool
 	}{
 		{
-			name: "without_cache",
-			env:  want,
+			name: "first_value",
+			env:  abs1,
+			want: abs1,
 		},
 		{
-			name: "with_cache",
-			env:  "TEST2",
+			name: "changed_value",
+			env:  abs2,
+			want: abs2,
+		},
+		{
+			name:    "relative_path",
+			env:     "relative/path",
+			wantErr: true,
 		},
 	}
 
@@ -27,9 +37,55 @@ func TestHomeDir(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Setenv("HOME", tt.env)
 			t.Setenv("USERPROFILE", tt.env)
-			if got := HomeDir(); got != want {
-				t.Errorf("HomeDir() = %q, want %q", got, want)
+
+			got, err := HomeDir()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("HomeDir() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("HomeDir() = %q, want %q", got, tt.want)
 			}
 		})
 	}
 }
+
+func TestHomeDirFallback(t *testing.T) {
+	ResetHomeDir()
+	t.Cleanup(ResetHomeDir)
+	t.Setenv("HOME", "")
+	t.Setenv("USERPROFILE", "")
+
+	var wg sync.WaitGroup
+	results := make([]string, 8)
+	for i := range results {
+		wg.Go(func() {
+			results[i], _ = HomeDir()
+		})
+	}
+	wg.Wait()
+
+	want, err := HomeDir()
+	if err != nil {
+		t.Skipf("HomeDir() error = %v (no user database entry)", err)
+	}
+	for _, got := range results {
+		if got != want {
+			t.Errorf("HomeDir() = %q, want %q", got, want)
+		}
+	}
+
+	homeDirMu.Lock()
+	cached := cachedHomeDir
+	homeDirMu.Unlock()
+	if cached != want {
+		t.Errorf("cachedHomeDir = %q, want %q", cached, want)
+	}
+
+	ResetHomeDir()
+	homeDirMu.Lock()
+	cached = cachedHomeDir
+	homeDirMu.Unlock()
+	if cached != "" {
+		t.Errorf("cachedHomeDir after ResetHomeDir() = %q, want %q", cached, "")
+	}
+}
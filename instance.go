@@ -0,0 +1,137 @@
+package xdg
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"net"
+	"os"
+	"path/filepath"
+)
+
+const (
+	instanceLockName   = "instance"
+	instanceSocketName = "instance.sock"
+)
+
+// Instance is the result of [ClaimInstance]. It's either the primary
+// instance of an app, or a secondary instance that should forward
+// its request to the primary one, and then exit.
+type Instance struct {
+	// Primary is true if this process has claimed the single instance
+	// of the app, and false if another process has already claimed it.
+	Primary bool
+
+	// SocketPath is the path of a Unix domain socket in the app's directory.
+	// The primary instance should listen on it, and secondary instances
+	// can connect to it, to forward their arguments to the primary one.
+	SocketPath string
+
+	// Owner identifies the primary instance. In a secondary instance, it's the
+	// process that holds the claim (or nil if it's unknown). In the primary
+	// instance, it's a previous owner that exited without releasing
+	// the claim (e.g. it crashed), or nil if there wasn't one.
+	Owner *LockOwner
+
+	lock *FileLock
+}
+
+// ClaimInstance enforces "only one copy of this app per user" semantics. It tries
+// to claim the single instance of the given app, under the given XDG base
+// directory, typically [RuntimeDir] (or [StateHome], if it needs to survive
+// logouts). It doesn't wait if the instance is already claimed.
+//
+// The claim is based on an exclusive [FileLock], so it's released automatically
+// when the primary instance exits, even if it crashes. The primary instance
+// also removes any stale socket file that a previous owner left behind,
+// but it's responsible for listening on [Instance.SocketPath], preferably
+// with [Instance.Listen], which handles paths that are too long for sockets.
+//
+// Note: this function normalizes the app name and ensures it doesn't
+// contain path elements, but the caller is responsible for input vetting.
+func ClaimInstance(dirType func() (string, error), appName string) (*Instance, error) {
+	path, err := CreateDir(dirType, appName)
+	if err != nil {
+		return nil, err
+	}
+
+	i := &Instance{SocketPath: filepath.Join(path, instanceSocketName)}
+	l, err := TryLockFile(dirType, appName, instanceLockName, true)
+	switch {
+	case errors.Is(err, ErrLocked):
+		i.Owner = readLockOwnerFile(filepath.Join(path, instanceLockName+lockFileSuffix))
+		return i, nil
+	case err != nil:
+		return nil, err
+	}
+
+	i.Primary, i.Owner, i.lock = true, l.StaleOwner, l
+	if err := removeStaleSocket(i.SocketPath); err != nil {
+		return nil, errors.Join(err, l.Unlock())
+	}
+
+	return i, nil
+}
+
+// Listen listens on the socket of a primary instance, like [ListenUnix].
+// Secondary instances should use [Instance.Dial] instead.
+func (i *Instance) Listen() (net.Listener, error) {
+	if !i.Primary {
+		return nil, errors.New("secondary instance must not listen")
+	}
+	return listenUnix(i.SocketPath)
+}
+
+// Dial connects to the socket of the primary instance, like [DialUnix].
+func (i *Instance) Dial(ctx context.Context) (net.Conn, error) {
+	return dialUnix(ctx, i.SocketPath)
+}
+
+// Release releases the claim of a primary instance, and removes its socket file,
+// if it exists. The socket should be closed before calling this function.
+// This is a no-op in secondary instances.
+func (i *Instance) Release() error {
+	if !i.Primary || i.lock == nil {
+		return nil
+	}
+
+	err := removeStaleSocket(i.SocketPath)
+	if short, shortErr := shortSocketPath(i.SocketPath); shortErr == nil && short != i.SocketPath {
+		err = errors.Join(err, removeStaleSocket(short))
+	}
+	err = errors.Join(err, i.lock.Unlock())
+	i.lock = nil
+
+	return err
+}
+
+// removeStaleSocket removes the given Unix domain socket file, if it exists.
+// It refuses to remove other types of files. The caller must ensure that no
+// other process uses the socket, e.g. by holding an exclusive [FileLock].
+func removeStaleSocket(path string) error {
+	info, err := os.Lstat(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+
+	if info.Mode().Type() != fs.ModeSocket {
+		return &os.PathError{Op: "remove", Path: path, Err: errors.New("not a socket file")}
+	}
+
+	return os.Remove(path)
+}
+
+// readLockOwnerFile reads the owner of an exclusive lock from the given lock file.
+// It returns nil if the file doesn't exist, is empty, or is malformed.
+func readLockOwnerFile(path string) *LockOwner {
+	f, err := os.Open(path) //gosec:disable G304
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	return readLockOwner(f)
+}
@@ -0,0 +1,111 @@
+package xdg
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// shortTempDir is like [testing.T.TempDir], but its path is short enough
+// for Unix domain socket paths, which are limited to ~100 bytes.
+func shortTempDir(t *testing.T) string {
+	t.Helper()
+
+	dir, err := os.MkdirTemp("", "xdg") //nolint:usetesting // t.TempDir() may exceed the sun_path limit.
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+	return dir
+}
+
+func TestClaimInstance(t *testing.T) {
+	dir := shortTempDir(t)
+	dirType := dirForTest(dir, nil)
+
+	// Simulate a crashed primary instance.
+	if err := os.MkdirAll(filepath.Join(dir, "my_app"), NewDirectoryPermissions); err != nil {
+		t.Fatal(err)
+	}
+	stale, err := net.Listen("unix", filepath.Join(dir, "my_app", instanceSocketName))
+	if err != nil {
+		t.Skipf("Unix domain sockets are not supported: %v", err)
+	}
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	if err := stale.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	primary, err := ClaimInstance(dirType, "my_app")
+	if err != nil {
+		t.Fatalf("ClaimInstance() error = %v", err)
+	}
+	if !primary.Primary {
+		t.Fatal("ClaimInstance() Primary = false, want true")
+	}
+	if _, err := os.Lstat(primary.SocketPath); !os.IsNotExist(err) {
+		t.Errorf("ClaimInstance() did not remove stale socket: %v", err)
+	}
+
+	ln, err := net.Listen("unix", primary.SocketPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	secondary, err := ClaimInstance(dirType, "my_app")
+	if err != nil {
+		t.Fatalf("ClaimInstance() error = %v", err)
+	}
+	if secondary.Primary {
+		t.Error("second ClaimInstance() Primary = true, want false")
+	}
+	if secondary.SocketPath != primary.SocketPath {
+		t.Errorf("second ClaimInstance() SocketPath = %q, want %q", secondary.SocketPath, primary.SocketPath)
+	}
+	if secondary.Owner == nil || secondary.Owner.PID != os.Getpid() {
+		t.Errorf("second ClaimInstance() Owner = %v, want PID %d", secondary.Owner, os.Getpid())
+	}
+
+	conn, err := net.Dial("unix", secondary.SocketPath)
+	if err != nil {
+		t.Fatalf("failed to connect to primary instance: %v", err)
+	}
+	if err := conn.Close(); err != nil {
+		t.Error(err)
+	}
+
+	if err := secondary.Release(); err != nil {
+		t.Errorf("secondary Release() error = %v", err)
+	}
+	ln.(*net.UnixListener).SetUnlinkOnClose(false)
+	if err := ln.Close(); err != nil {
+		t.Error(err)
+	}
+	if err := primary.Release(); err != nil {
+		t.Errorf("primary Release() error = %v", err)
+	}
+
+	again, err := ClaimInstance(dirType, "my_app")
+	if err != nil || !again.Primary || again.Owner != nil {
+		t.Errorf("ClaimInstance() after Release() = %+v, %v", again, err)
+	}
+	if err := again.Release(); err != nil {
+		t.Errorf("Release() error = %v", err)
+	}
+}
+
+func TestRemoveStaleSocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "file")
+	if err := removeStaleSocket(path); err != nil {
+		t.Errorf("removeStaleSocket() with missing file error = %v", err)
+	}
+
+	if err := os.WriteFile(path, nil, NewFilePermissions); err != nil {
+		t.Fatal(err)
+	}
+	if err := removeStaleSocket(path); err == nil {
+		t.Error("removeStaleSocket() with regular file error = nil, want error")
+	}
+}
@@ -0,0 +1,125 @@
+package xdg
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+)
+
+// ConfigSources maps the keys of a layered configuration to the paths of the
+// files that supplied their final values. Keys are JSON Pointers (RFC 6901),
+// e.g. "/server/port", so "~" and "/" in key names are escaped as "~0" and
+// "~1" respectively. Only leaf values (i.e. non-objects) are listed.
+type ConfigSources map[string]string
+
+// jsonPointerEscaper escapes a key name to be used as a JSON Pointer token.
+var jsonPointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+// LoadJSONConfig reads an app's JSON configuration file from all the [ConfigDirs]
+// and [ConfigHome] directories, deep-merges their objects, and then decodes the
+// result into v, which is typically a pointer to a struct. Files with higher
+// precedence override the values of files with lower precedence, except for
+// nested objects, which are merged recursively. Arrays are not merged.
+//
+// Note: the filePath parameter may contain 0 or more path elements before the file
+// name (e.g. "config.json"), like in [FindConfigFile]. If no file is found, v is
+// not modified, and this function returns an empty map but no error.
+func LoadJSONConfig(appName, filePath string, v any) (ConfigSources, error) {
+	return defaultResolver.LoadJSONConfig(appName, filePath, v)
+}
+
+// LoadJSONConfig is like the package-level [LoadJSONConfig], but uses r's environment.
+func (r *Resolver) LoadJSONConfig(appName, filePath string, v any) (ConfigSources, error) {
+	files, err := r.FindAllConfigFiles(appName, filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	merged := map[string]any{}
+	sources := ConfigSources{}
+	for _, f := range slices.Backward(files) {
+		layer, err := readJSONObject(f.Path)
+		if err != nil {
+			return nil, err
+		}
+		mergeJSONObjects(merged, layer, "", f.Path, sources)
+	}
+
+	if len(files) == 0 {
+		return sources, nil
+	}
+
+	data, err := json.Marshal(merged)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return nil, fmt.Errorf("failed to decode merged JSON config: %w", err)
+	}
+
+	return sources, nil
+}
+
+// readJSONObject reads a JSON file which must contain a single object. Numbers
+// are decoded as [json.Number] to preserve their precision during merging.
+func readJSONObject(path string) (map[string]any, error) {
+	data, err := os.ReadFile(path) //gosec:disable G304
+	if err != nil {
+		return nil, err
+	}
+
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+
+	var obj map[string]any
+	if err := d.Decode(&obj); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON config %q: %w", path, err)
+	}
+	if obj == nil {
+		return nil, fmt.Errorf("JSON config %q is not an object", path)
+	}
+	if _, err := d.Token(); !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("JSON config %q contains data after the top-level object", path)
+	}
+
+	return obj, nil
+}
+
+// mergeJSONObjects deep-merges src into dst, and records in sources the
+// file that supplied each leaf value under the given key prefix.
+func mergeJSONObjects(dst, src map[string]any, prefix, source string, sources ConfigSources) {
+	for _, k := range slices.Sorted(maps.Keys(src)) {
+		key := prefix + "/" + jsonPointerEscaper.Replace(k)
+		srcObj, srcIsObj := src[k].(map[string]any)
+		dstObj, dstIsObj := dst[k].(map[string]any)
+
+		if srcIsObj && dstIsObj {
+			mergeJSONObjects(dstObj, srcObj, key, source, sources)
+			continue
+		}
+
+		// Replace the entire value, including all its previous sources.
+		for s := range sources {
+			if strings.HasPrefix(s, key+"/") {
+				delete(sources, s)
+			}
+		}
+
+		if !srcIsObj {
+			dst[k] = src[k]
+			sources[key] = source
+			continue
+		}
+
+		delete(sources, key)
+		dstObj = map[string]any{}
+		dst[k] = dstObj
+		mergeJSONObjects(dstObj, srcObj, key, source, sources)
+	}
+}
@@ -0,0 +1,123 @@
+package xdg
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestLoadJSONConfig(t *testing.T) {
+	home, sys1, sys2 := t.TempDir(), t.TempDir(), t.TempDir()
+	r := &Resolver{
+		Getenv: mapEnv(map[string]string{
+			"XDG_CONFIG_HOME": home,
+			"XDG_CONFIG_DIRS": sys1 + listSeparator + sys2,
+		}),
+	}
+
+	files := map[string]string{
+		sys2: `{"name": "default", "port": 80, "tags": ["a", "b"], "db": {"host": "localhost", "port": 5432}}`,
+		sys1: `{"port": 8080, "db": {"host": "db.example.com"}, "extra": {"x": 1}}`,
+		home: `{"tags": ["c"], "db": {"user": "me"}, "extra": 2}`,
+	}
+	for dir, content := range files {
+		path := filepath.Join(dir, "my_app", "config.json")
+		if err := os.MkdirAll(filepath.Dir(path), NewDirectoryPermissions); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), NewFilePermissions); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	type config struct {
+		Name string   `json:"name"`
+		Port int      `json:"port"`
+		Tags []string `json:"tags"`
+		DB   struct {
+			Host string `json:"host"`
+			Port int    `json:"port"`
+			User string `json:"user"`
+		} `json:"db"`
+		Extra int `json:"extra"`
+	}
+
+	var got config
+	sources, err := r.LoadJSONConfig("my_app", "config.json", &got)
+	if err != nil {
+		t.Fatalf("LoadJSONConfig() error = %v", err)
+	}
+
+	want := config{Name: "default", Port: 8080, Tags: []string{"c"}, Extra: 2}
+	want.DB.Host, want.DB.Port, want.DB.User = "db.example.com", 5432, "me"
+	if !slices.Equal(got.Tags, want.Tags) || got.Name != want.Name || got.Port != want.Port ||
+		got.DB != want.DB || got.Extra != want.Extra {
+		t.Errorf("LoadJSONConfig() = %+v, want %+v", got, want)
+	}
+
+	file := func(dir string) string { return filepath.Join(dir, "my_app", "config.json") }
+	wantSources := ConfigSources{
+		"/name":    file(sys2),
+		"/port":    file(sys1),
+		"/tags":    file(home),
+		"/db/host": file(sys1),
+		"/db/port": file(sys2),
+		"/db/user": file(home),
+		"/extra":   file(home),
+	}
+	if !maps.Equal(sources, wantSources) {
+		t.Errorf("LoadJSONConfig() sources = %v, want %v", sources, wantSources)
+	}
+}
+
+func TestLoadJSONConfigErrors(t *testing.T) {
+	home := t.TempDir()
+	r := &Resolver{
+		Getenv: mapEnv(map[string]string{"XDG_CONFIG_HOME": home}),
+	}
+
+	var v map[string]any
+	sources, err := r.LoadJSONConfig("my_app", "config.json", &v)
+	if err != nil || len(sources) != 0 || v != nil {
+		t.Errorf("LoadJSONConfig() without files = %v, %v, %v", v, sources, err)
+	}
+
+	path := filepath.Join(home, "my_app", "config.json")
+	if err := os.MkdirAll(filepath.Dir(path), NewDirectoryPermissions); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, content := range []string{`[1, 2]`, `null`, `{"a": `, `{"a": 1} {"b": 2}`, `{} x`} {
+		if err := os.WriteFile(path, []byte(content), NewFilePermissions); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := r.LoadJSONConfig("my_app", "config.json", &v); err == nil {
+			t.Errorf("LoadJSONConfig() with %q error = nil, wantErr true", content)
+		}
+	}
+}
+
+func TestMergeJSONObjects(t *testing.T) {
+	dst := map[string]any{}
+	sources := ConfigSources{}
+
+	mergeJSONObjects(dst, map[string]any{"a": map[string]any{"b": 1, "c": 2}}, "", "f1", sources)
+	mergeJSONObjects(dst, map[string]any{"a": 3}, "", "f2", sources)
+	if want := (ConfigSources{"/a": "f2"}); !maps.Equal(sources, want) {
+		t.Errorf("sources after replacing object = %v, want %v", sources, want)
+	}
+
+	mergeJSONObjects(dst, map[string]any{"a": map[string]any{"d": 4}}, "", "f3", sources)
+	if want := (ConfigSources{"/a/d": "f3"}); !maps.Equal(sources, want) {
+		t.Errorf("sources after replacing scalar = %v, want %v", sources, want)
+	}
+
+	mergeJSONObjects(dst, map[string]any{"a.d": 5, "a/d": 6, "a~": 7}, "", "f4", sources)
+	mergeJSONObjects(dst, map[string]any{"a": 8}, "", "f5", sources)
+	want := ConfigSources{"/a": "f5", "/a.d": "f4", "/a~1d": "f4", "/a~0": "f4"}
+	if !maps.Equal(sources, want) {
+		t.Errorf("sources with special key names = %v, want %v", sources, want)
+	}
+}
@@ -0,0 +1,231 @@
+package xdg
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// keyFile is a parsed file in the INI-like format of the FreeDesktop.org Desktop
+// Entry specification, which is also used by other specifications, such as the
+// MIME applications associations ("mimeapps.list").
+type keyFile struct {
+	groups []*keyGroup
+}
+
+// keyGroup is a named group of keys in a [keyFile]. Localized keys
+// are stored with their locale suffix, e.g. "Name[de_DE]".
+type keyGroup struct {
+	name   string
+	keys   []string
+	values map[string]string
+}
+
+// parseKeyFile parses a key file. Comments and blank lines are ignored, and so
+// are keys before the first group. Duplicate groups are merged, and duplicate
+// keys override the previous ones. Values are not unescaped.
+func parseKeyFile(data []byte) (*keyFile, error) {
+	kf := &keyFile{}
+	var g *keyGroup
+
+	s := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; s.Scan(); n++ {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			name, ok := strings.CutSuffix(line[1:], "]")
+			if !ok || name == "" || strings.ContainsAny(name, "[]") {
+				return nil, fmt.Errorf("line %d: invalid group header %q", n, line)
+			}
+			g = kf.addGroup(name)
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: invalid key-value pair %q", n, line)
+		}
+		if g != nil {
+			g.set(strings.TrimSpace(key), strings.TrimSpace(value))
+		}
+	}
+
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	return kf, nil
+}
+
+// group returns the group with the given name, or nil if it doesn't exist.
+func (kf *keyFile) group(name string) *keyGroup {
+	for _, g := range kf.groups {
+		if g.name == name {
+			return g
+		}
+	}
+	return nil
+}
+
+// addGroup returns the group with the given name, and adds it if it doesn't exist yet.
+func (kf *keyFile) addGroup(name string) *keyGroup {
+	if g := kf.group(name); g != nil {
+		return g
+	}
+
+	g := &keyGroup{name: name, values: map[string]string{}}
+	kf.groups = append(kf.groups, g)
+	return g
+}
+
+// bytes returns the key file's content, with groups and keys in their original order.
+func (kf *keyFile) bytes() []byte {
+	var b bytes.Buffer
+	for i, g := range kf.groups {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "[%s]\n", g.name)
+		for _, k := range g.keys {
+			fmt.Fprintf(&b, "%s=%s\n", k, g.values[k])
+		}
+	}
+	return b.Bytes()
+}
+
+// value returns the raw value of a key, and whether it exists. A nil group has no keys.
+func (g *keyGroup) value(key string) (string, bool) {
+	if g == nil {
+		return "", false
+	}
+	v, ok := g.values[key]
+	return v, ok
+}
+
+// localeValue returns the raw value of a localized key, according to the
+// locale matching rules of the Desktop Entry specification: a locale in the
+// form "lang_COUNTRY.ENCODING@MODIFIER" matches "lang_COUNTRY@MODIFIER",
+// "lang_COUNTRY", "lang@MODIFIER", "lang", and then the unlocalized key.
+func (g *keyGroup) localeValue(key, locale string) (string, bool) {
+	for _, l := range localeCandidates(locale) {
+		if v, ok := g.value(key + "[" + l + "]"); ok {
+			return v, true
+		}
+	}
+	return g.value(key)
+}
+
+// set sets the raw value of a key, and adds it if it doesn't exist yet.
+func (g *keyGroup) set(key, value string) {
+	if _, ok := g.values[key]; !ok {
+		g.keys = append(g.keys, key)
+	}
+	g.values[key] = value
+}
+
+// localeCandidates returns the localized key suffixes to look for, in order
+// of precedence, for the given POSIX locale (e.g. "de_DE.UTF-8@euro").
+func localeCandidates(locale string) []string {
+	locale, modifier, _ := strings.Cut(locale, "@")
+	locale, _, _ = strings.Cut(locale, ".")
+	lang, country, _ := strings.Cut(locale, "_")
+	if lang == "" || lang == "C" || lang == "POSIX" {
+		return nil
+	}
+
+	var candidates []string
+	if country != "" && modifier != "" {
+		candidates = append(candidates, lang+"_"+country+"@"+modifier)
+	}
+	if country != "" {
+		candidates = append(candidates, lang+"_"+country)
+	}
+	if modifier != "" {
+		candidates = append(candidates, lang+"@"+modifier)
+	}
+	return append(candidates, lang)
+}
+
+// unescapeValue unescapes a string value: "\s", "\n", "\t", "\r", and "\\".
+// Other escape sequences (e.g. "\;" in lists) are kept as-is.
+func unescapeValue(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i == len(s)-1 {
+			b.WriteByte(s[i])
+			continue
+		}
+
+		i++
+		switch s[i] {
+		case 's':
+			b.WriteByte(' ')
+		case 'n':
+			b.WriteByte('\n')
+		case 't':
+			b.WriteByte('\t')
+		case 'r':
+			b.WriteByte('\r')
+		case '\\':
+			b.WriteByte('\\')
+		default:
+			b.WriteByte('\\')
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
+
+// escapeValue is the inverse of [unescapeValue].
+func escapeValue(s string) string {
+	s = strings.NewReplacer(`\`, `\\`, "\n", `\n`, "\t", `\t`, "\r", `\r`).Replace(s)
+	if strings.HasPrefix(s, " ") {
+		s = `\s` + s[1:]
+	}
+	return s
+}
+
+// splitList splits a list value, which is separated (and optionally
+// terminated) by ";", and unescapes its items, including "\;".
+func splitList(s string) []string {
+	var items []string
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && i < len(s)-1 && s[i+1] == ';':
+			b.WriteByte(';')
+			i++
+		case s[i] == '\\' && i < len(s)-1:
+			b.WriteByte(s[i])
+			b.WriteByte(s[i+1])
+			i++
+		case s[i] == ';':
+			items = append(items, unescapeValue(b.String()))
+			b.Reset()
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+
+	if b.Len() > 0 {
+		items = append(items, unescapeValue(b.String()))
+	}
+	return items
+}
+
+// joinList is the inverse of [splitList].
+func joinList(items []string) string {
+	var b strings.Builder
+	for _, item := range items {
+		b.WriteString(strings.ReplaceAll(escapeValue(item), ";", `\;`))
+		b.WriteString(";")
+	}
+	return b.String()
+}
@@ -0,0 +1,130 @@
+package xdg
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseKeyFile(t *testing.T) {
+	data := []byte(`# Comment
+ignored=before group
+
+[Group 1]
+Key = value
+Name[de]=Wert
+Key=override
+
+[Group 2]
+Other=x
+
+[Group 1]
+Merged=true
+`)
+
+	kf, err := parseKeyFile(data)
+	if err != nil {
+		t.Fatalf("parseKeyFile() error = %v", err)
+	}
+	if len(kf.groups) != 2 {
+		t.Fatalf("parseKeyFile() groups = %d, want 2", len(kf.groups))
+	}
+
+	g := kf.group("Group 1")
+	for key, want := range map[string]string{"Key": "override", "Name[de]": "Wert", "Merged": "true"} {
+		if got, ok := g.value(key); !ok || got != want {
+			t.Errorf("value(%q) = %q, %v, want %q", key, got, ok, want)
+		}
+	}
+	if _, ok := kf.group("Group 3").value("Key"); ok {
+		t.Error("value() in missing group = true, want false")
+	}
+
+	want := "[Group 1]\nKey=override\nName[de]=Wert\nMerged=true\n\n[Group 2]\nOther=x\n"
+	if got := string(kf.bytes()); got != want {
+		t.Errorf("bytes() = %q, want %q", got, want)
+	}
+
+	for _, bad := range []string{"[Group", "[Group]\nno equals sign", "[]"} {
+		if _, err := parseKeyFile([]byte(bad)); err == nil {
+			t.Errorf("parseKeyFile(%q) error = nil, want error", bad)
+		}
+	}
+}
+
+func TestLocaleValue(t *testing.T) {
+	kf, err := parseKeyFile([]byte("[G]\nName=C\nName[sr]=sr\nName[sr_YU]=sr_YU\n" +
+		"Name[sr@Latn]=sr@Latn\nName[sr_YU@Latn]=sr_YU@Latn\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := kf.group("G")
+
+	tests := []struct {
+		locale string
+		want   string
+	}{
+		{locale: "", want: "C"},
+		{locale: "C.UTF-8", want: "C"},
+		{locale: "de_DE.UTF-8", want: "C"},
+		{locale: "sr", want: "sr"},
+		{locale: "sr_YU.UTF-8", want: "sr_YU"},
+		{locale: "sr_CS@Latn", want: "sr@Latn"},
+		{locale: "sr_YU.UTF-8@Latn", want: "sr_YU@Latn"},
+	}
+
+	for _, tt := range tests {
+		if got, _ := g.localeValue("Name", tt.locale); got != tt.want {
+			t.Errorf("localeValue(%q) = %q, want %q", tt.locale, got, tt.want)
+		}
+	}
+}
+
+func TestKeyFileValueEscapes(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want string
+	}{
+		{name: "plain", raw: "abc", want: "abc"},
+		{name: "escapes", raw: `a\sb\nc\td\re\\f`, want: "a b\nc\td\re\\f"},
+		{name: "unknown", raw: `a\;b`, want: `a\;b`},
+		{name: "trailing_backslash", raw: `a\`, want: `a\`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := unescapeValue(tt.raw); got != tt.want {
+				t.Errorf("unescapeValue() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if got := unescapeValue(escapeValue(" a\\b\n")); got != " a\\b\n" {
+		t.Errorf("escapeValue() round trip = %q", got)
+	}
+}
+
+func TestSplitList(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want []string
+	}{
+		{name: "empty"},
+		{name: "terminated", raw: "a;b;", want: []string{"a", "b"}},
+		{name: "unterminated", raw: "a;b", want: []string{"a", "b"}},
+		{name: "escaped_separator", raw: `a\;b;c\sd;`, want: []string{"a;b", "c d"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := splitList(tt.raw)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("splitList() = %q, want %q", got, tt.want)
+			}
+			if len(got) > 0 && !slices.Equal(splitList(joinList(got)), got) {
+				t.Errorf("joinList() round trip = %q", joinList(got))
+			}
+		})
+	}
+}
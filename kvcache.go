@@ -0,0 +1,268 @@
+package xdg
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	kvSubdir       = "kv"
+	kvFileSuffix   = ".entry"
+	kvHeaderPrefix = "expires: "
+	kvNever        = "never"
+
+	// maxKVNameLen keeps file names well below the common limit of 255 bytes.
+	maxKVNameLen = 200
+)
+
+// KVCache is a small file-backed key/value cache in an app's cache directory,
+// which was opened by [OpenKVCache]. Each entry is stored in a separate
+// file, along with its expiry time, and it's replaced atomically.
+//
+// It's safe to use a cache concurrently, even in different processes.
+// Entries may be evicted at any time by [TrimCache], so callers should
+// treat missing entries as cache misses.
+type KVCache struct {
+	// Path is the full path of the cache's directory: "<CacheHome>/<app>/kv".
+	Path string
+}
+
+// OpenKVCache returns the key/value [KVCache] of the given app, under
+// [CacheHome]. It creates the cache's directory if it doesn't exist yet.
+//
+// Note: this function normalizes the app name and ensures it doesn't
+// contain path elements, but the caller is responsible for input vetting.
+func OpenKVCache(appName string) (*KVCache, error) {
+	return defaultResolver.OpenKVCache(appName)
+}
+
+// OpenKVCache is like the package-level [OpenKVCache], but uses r's environment.
+func (r *Resolver) OpenKVCache(appName string) (*KVCache, error) {
+	if err := r.checkHost(); err != nil {
+		return nil, err
+	}
+	return openKVCache(r.CacheHome, appName)
+}
+
+func openKVCache(dirType func() (string, error), appName string) (*KVCache, error) {
+	path, err := CreateSubdir(dirType, appName, kvSubdir)
+	if err != nil {
+		return nil, err
+	}
+	return &KVCache{Path: path}, nil
+}
+
+// Set stores the value of the given key, which expires after the given
+// duration. A non-positive TTL means that the entry never expires.
+// Keys may contain any characters: they are sanitized to file names.
+func (c *KVCache) Set(key string, value []byte, ttl time.Duration) error {
+	name, err := kvFileName(key)
+	if err != nil {
+		return err
+	}
+
+	expires := kvNever
+	if ttl > 0 {
+		expires = time.Now().Add(ttl).UTC().Format(time.RFC3339Nano)
+	}
+
+	data := append([]byte(kvHeaderPrefix+expires+"\n"), value...)
+	return writeFileAtomic(c.Path, name, data, NewFilePermissions)
+}
+
+// Get returns the value of the given key, and true if it exists and
+// it hasn't expired yet. Expired entries are considered cache misses.
+func (c *KVCache) Get(key string) ([]byte, bool, error) {
+	name, err := kvFileName(key)
+	if err != nil {
+		return nil, false, err
+	}
+
+	value, expires, _, err := c.readEntry(name)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+
+	if !expires.IsZero() && !time.Now().Before(expires) {
+		return nil, false, nil
+	}
+
+	return value, true, nil
+}
+
+// Delete removes the given key, if it exists.
+func (c *KVCache) Delete(key string) error {
+	name, err := kvFileName(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(filepath.Join(c.Path, name))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// Sweep removes all the expired entries, as well as malformed ones, and
+// returns the number of removed entries. It doesn't remove entries that are
+// replaced concurrently by [KVCache.Set], but [KVCache.Get] may briefly
+// miss them while they're being checked.
+func (c *KVCache) Sweep() (int, error) {
+	entries, err := os.ReadDir(c.Path)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	removed := 0
+	var errs []error
+	for _, e := range entries {
+		if !e.Type().IsRegular() || !strings.HasSuffix(e.Name(), kvFileSuffix) {
+			continue
+		}
+
+		_, expires, info, err := c.readEntry(e.Name())
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			continue
+		case errors.Is(err, errMalformedKVEntry):
+			// Remove it.
+		case err != nil:
+			errs = append(errs, err)
+			continue
+		case expires.IsZero() || now.Before(expires):
+			continue
+		}
+
+		ok, err := removeKVEntry(filepath.Join(c.Path, e.Name()), info)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		if ok {
+			removed++
+		}
+	}
+
+	return removed, errors.Join(errs...)
+}
+
+var errMalformedKVEntry = errors.New("malformed cache entry")
+
+// readEntry reads a cache entry file, and returns its value, its expiry time
+// (zero if it never expires), and the file's details, for [removeKVEntry].
+func (c *KVCache) readEntry(name string) ([]byte, time.Time, fs.FileInfo, error) {
+	f, err := os.Open(filepath.Join(c.Path, name)) //gosec:disable G304
+	if err != nil {
+		return nil, time.Time{}, nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, time.Time{}, nil, err
+	}
+
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil, time.Time{}, nil, err
+	}
+
+	header, value, ok := bytes.Cut(data, []byte("\n"))
+	expires, found := strings.CutPrefix(string(header), kvHeaderPrefix)
+	if !ok || !found {
+		return nil, time.Time{}, info, fmt.Errorf("%w: %q", errMalformedKVEntry, f.Name())
+	}
+	if expires == kvNever {
+		return value, time.Time{}, info, nil
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, expires)
+	if err != nil {
+		return nil, time.Time{}, info, fmt.Errorf("%w: %q", errMalformedKVEntry, f.Name())
+	}
+
+	return value, t, info, nil
+}
+
+// removeKVEntry removes a cache entry file, unless it was replaced by another
+// process since it was read. To make the check and the removal atomic, it moves
+// the file aside first, and then restores it if it turns out to be a replacement.
+func removeKVEntry(path string, info fs.FileInfo) (bool, error) {
+	dir, name := filepath.Split(path)
+	tempPath := filepath.Join(dir, "."+name+".tmp"+rand.Text())
+	if err := os.Rename(path, tempPath); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	current, err := os.Lstat(tempPath)
+	if err != nil {
+		return false, err
+	}
+	if os.SameFile(info, current) {
+		return true, os.Remove(tempPath)
+	}
+
+	// Restore the replacement, unless it was replaced again in the meantime.
+	err = os.Link(tempPath, path)
+	if err == nil || errors.Is(err, fs.ErrExist) {
+		return false, os.Remove(tempPath)
+	}
+	return false, os.Rename(tempPath, path) // Hard links aren't supported.
+}
+
+// windowsReservedNames can't be used as file names in Windows,
+// regardless of their case, and even with an extension.
+var windowsReservedNames = []string{
+	"con", "prn", "aux", "nul",
+	"com1", "com2", "com3", "com4", "com5", "com6", "com7", "com8", "com9",
+	"lpt1", "lpt2", "lpt3", "lpt4", "lpt5", "lpt6", "lpt7", "lpt8", "lpt9",
+}
+
+// kvFileName sanitizes a cache key into a portable file name. Lowercase
+// letters, digits, "-" and "_" are kept as-is, other bytes are escaped as
+// "%XX" (including uppercase letters, because some filesystems are case
+// insensitive). Long keys are replaced by their SHA-256 digests.
+func kvFileName(key string) (string, error) {
+	if key == "" {
+		return "", errors.New("cache key is empty")
+	}
+
+	var sb strings.Builder
+	for i := range len(key) {
+		switch b := key[i]; {
+		case 'a' <= b && b <= 'z', '0' <= b && b <= '9', b == '-', b == '_':
+			sb.WriteByte(b)
+		default:
+			fmt.Fprintf(&sb, "%%%02X", b)
+		}
+	}
+
+	name := sb.String()
+	if slices.Contains(windowsReservedNames, name) {
+		name = fmt.Sprintf("%%%02X", name[0]) + name[1:]
+	}
+	if len(name) > maxKVNameLen {
+		sum := sha256.Sum256([]byte(key))
+		name = "~" + hex.EncodeToString(sum[:])
+	}
+
+	return name + kvFileSuffix, nil
+}
@@ -0,0 +1,172 @@
+package xdg
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestKVCache(t *testing.T) {
+	c, err := openKVCache(dirForTest(t.TempDir(), nil), "my_app")
+	if err != nil {
+		t.Fatalf("openKVCache() error = %v", err)
+	}
+
+	if err := c.Set("forever", []byte("value 1\nline 2"), 0); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if err := c.Set("fresh", []byte("value 2"), time.Hour); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if err := c.Set("expired", []byte("value 3"), time.Nanosecond); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	time.Sleep(time.Millisecond)
+
+	tests := []struct {
+		key    string
+		want   string
+		wantOK bool
+	}{
+		{key: "forever", want: "value 1\nline 2", wantOK: true},
+		{key: "fresh", want: "value 2", wantOK: true},
+		{key: "expired"},
+		{key: "missing"},
+	}
+	for _, tt := range tests {
+		got, ok, err := c.Get(tt.key)
+		if err != nil {
+			t.Errorf("Get(%q) error = %v", tt.key, err)
+		}
+		if string(got) != tt.want || ok != tt.wantOK {
+			t.Errorf("Get(%q) = %q, %v, want %q, %v", tt.key, got, ok, tt.want, tt.wantOK)
+		}
+	}
+
+	// Malformed entries are removed by Sweep too.
+	bad := filepath.Join(c.Path, "bad"+kvFileSuffix)
+	if err := os.WriteFile(bad, []byte("garbage"), NewFilePermissions); err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := c.Sweep()
+	if err != nil {
+		t.Fatalf("Sweep() error = %v", err)
+	}
+	if n != 2 {
+		t.Errorf("Sweep() = %d, want 2", n)
+	}
+
+	entries, err := os.ReadDir(c.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("Sweep() left %d entries, want 2", len(entries))
+	}
+
+	if err := c.Delete("fresh"); err != nil {
+		t.Errorf("Delete() error = %v", err)
+	}
+	if _, ok, _ := c.Get("fresh"); ok {
+		t.Error("Get() after Delete() = true, want false")
+	}
+	if err := c.Delete("missing"); err != nil {
+		t.Errorf("Delete() missing key error = %v", err)
+	}
+}
+
+func TestRemoveKVEntry(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "key"+kvFileSuffix)
+	if err := os.WriteFile(path, []byte("old"), NewFilePermissions); err != nil {
+		t.Fatal(err)
+	}
+	old, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Simulate a concurrent Set after the entry was read.
+	if err := writeFileAtomic(dir, "key"+kvFileSuffix, []byte("new"), NewFilePermissions); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := removeKVEntry(path, old); ok || err != nil {
+		t.Errorf("removeKVEntry() with replaced file = %v, %v, want false", ok, err)
+	}
+	if got, err := os.ReadFile(path); string(got) != "new" {
+		t.Errorf("removeKVEntry() did not restore the replacement: %q, %v", got, err)
+	}
+
+	current, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := removeKVEntry(path, current); !ok || err != nil {
+		t.Errorf("removeKVEntry() = %v, %v, want true", ok, err)
+	}
+	if ok, err := removeKVEntry(path, current); ok || err != nil {
+		t.Errorf("removeKVEntry() with missing file = %v, %v, want false", ok, err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("removeKVEntry() left %d files, want 0", len(entries))
+	}
+}
+
+func TestKVFileName(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "plain",
+			key:  "abc-123_x",
+			want: "abc-123_x.entry",
+		},
+		{
+			name: "uppercase",
+			key:  "Key",
+			want: "%4Bey.entry",
+		},
+		{
+			name: "path_traversal",
+			key:  "../etc/passwd",
+			want: "%2E%2E%2Fetc%2Fpasswd.entry",
+		},
+		{
+			name: "windows_reserved",
+			key:  "nul",
+			want: "%6Eul.entry",
+		},
+		{
+			name: "long",
+			key:  strings.Repeat("a", 201),
+			want: "~a92efd82109373e58f9a2056dee01e807e216ce6075f7051207c0a9f7d666e50.entry",
+		},
+		{
+			name:    "empty",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := kvFileName(tt.key)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("kvFileName() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("kvFileName() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
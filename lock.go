@@ -0,0 +1,211 @@
+package xdg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	lockFileSuffix = ".lock"
+
+	minLockPollInterval = 10 * time.Millisecond
+	maxLockPollInterval = 250 * time.Millisecond
+)
+
+// ErrLocked is returned by [TryLockFile] if the
+// lock is already held by another process or file handle.
+var ErrLocked = errors.New("lock is held by someone else")
+
+// FileLock is an advisory lock on a file, which was acquired by
+// [LockFile] or [TryLockFile], and should be released by [FileLock.Unlock].
+type FileLock struct {
+	// Path is the full path of the lock file.
+	Path string
+
+	// StaleOwner is the previous owner of an exclusive lock, if it did not
+	// release the lock before exiting (e.g. it crashed). It's always nil
+	// for shared locks, and for exclusive locks which were released properly.
+	StaleOwner *LockOwner
+
+	f         *os.File
+	exclusive bool
+}
+
+// LockOwner identifies the process that holds an exclusive [FileLock].
+type LockOwner struct {
+	PID      int
+	Hostname string
+}
+
+// LockFile acquires an advisory lock on the given app's "<name>.lock" file
+// under the given XDG base directory, typically [StateHome] or [RuntimeDir].
+// It creates the file and any parent directories if they don't exist yet.
+//
+// Exclusive locks are held by a single owner, whose PID and hostname are
+// recorded in the lock file, to detect stale locks (see [FileLock.StaleOwner]).
+// Shared locks may be held by multiple owners at the same time, as long as
+// there is no exclusive owner. Locks are released automatically when the
+// owner process exits, but they should be released explicitly.
+//
+// This function waits until the lock is acquired, or the context is done.
+// Use [context.WithTimeout] to limit the waiting time.
+//
+// Note: this function normalizes the app and lock names and ensures they
+// don't contain path elements, but the caller is responsible for input vetting.
+func LockFile(
+	ctx context.Context,
+	dirType func() (string, error),
+	appName, name string,
+	exclusive bool,
+) (*FileLock, error) {
+	l, err := openLockFile(dirType, appName, name, exclusive)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := waitLockFile(ctx, l.f, exclusive); err != nil {
+		_ = l.f.Close()
+		return nil, fmt.Errorf("failed to lock %q: %w", l.Path, err)
+	}
+	if err := l.setOwner(); err != nil {
+		_ = l.f.Close()
+		return nil, err
+	}
+
+	return l, nil
+}
+
+// waitLockFile acquires a lock on the given file, and waits
+// as long as necessary, or until the context is done.
+func waitLockFile(ctx context.Context, f *os.File, exclusive bool) error {
+	interval := minLockPollInterval
+	for {
+		err := lockFile(f, exclusive)
+		if !errors.Is(err, ErrLocked) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+			interval = min(interval*2, maxLockPollInterval)
+		}
+	}
+}
+
+// TryLockFile is like [LockFile], but it doesn't wait: if the lock is
+// already held by someone else, it returns an error that wraps [ErrLocked].
+func TryLockFile(dirType func() (string, error), appName, name string, exclusive bool) (*FileLock, error) {
+	l, err := openLockFile(dirType, appName, name, exclusive)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := l.tryLock(); err != nil {
+		_ = l.f.Close()
+		return nil, fmt.Errorf("failed to lock %q: %w", l.Path, err)
+	}
+
+	return l, nil
+}
+
+// Unlock releases the lock. For exclusive locks, it also
+// clears the owner's details from the lock file.
+func (l *FileLock) Unlock() error {
+	if l.f == nil {
+		return errors.New("lock is not held")
+	}
+
+	var errs []error
+	if l.exclusive {
+		errs = append(errs, l.f.Truncate(0))
+	}
+	errs = append(errs, unlockFile(l.f), l.f.Close())
+	l.f = nil
+
+	return errors.Join(errs...)
+}
+
+func openLockFile(dirType func() (string, error), appName, name string, exclusive bool) (*FileLock, error) {
+	path, err := CreateFile(dirType, appName, name+lockFileSuffix)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.OpenFile(path, os.O_RDWR, NewFilePermissions) //gosec:disable G304
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileLock{Path: path, f: f, exclusive: exclusive}, nil
+}
+
+// tryLock tries to acquire the lock without waiting. If it succeeds
+// with an exclusive lock, it also records the owner in the lock file.
+func (l *FileLock) tryLock() error {
+	if err := lockFile(l.f, l.exclusive); err != nil {
+		return err
+	}
+	return l.setOwner()
+}
+
+// setOwner replaces the previous owner's details in an exclusive
+// lock file with the current process's, after acquiring the lock.
+func (l *FileLock) setOwner() error {
+	if !l.exclusive {
+		return nil
+	}
+
+	l.StaleOwner = readLockOwner(l.f)
+	if err := writeLockOwner(l.f); err != nil {
+		_ = unlockFile(l.f)
+		return err
+	}
+
+	return nil
+}
+
+// readLockOwner parses the lock owner's details from the given lock file.
+// It returns nil if the file is empty or malformed.
+func readLockOwner(f *os.File) *LockOwner {
+	data, err := io.ReadAll(io.NewSectionReader(f, 0, 1024))
+	if err != nil {
+		return nil
+	}
+
+	pid, hostname, ok := strings.Cut(strings.TrimSpace(string(data)), "\n")
+	if !ok {
+		return nil
+	}
+
+	n, err := strconv.Atoi(pid)
+	if err != nil || n <= 0 {
+		return nil
+	}
+
+	return &LockOwner{PID: n, Hostname: strings.TrimSpace(hostname)}
+}
+
+// writeLockOwner records the current process's details in the given lock file.
+func writeLockOwner(f *os.File) error {
+	hostname, err := os.Hostname()
+	if err != nil {
+		return err
+	}
+
+	if err := f.Truncate(0); err != nil {
+		return err
+	}
+	if _, err := f.WriteAt([]byte(fmt.Sprintf("%d\n%s\n", os.Getpid(), hostname)), 0); err != nil {
+		return err
+	}
+
+	return f.Sync()
+}
@@ -0,0 +1,36 @@
+package xdg
+
+import (
+	"errors"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// lockFile tries to acquire an fcntl(2)-style lock on the given file, without
+// waiting, because AIX doesn't support flock(2). Note that such locks are owned
+// by the process, not the file handle, so they don't conflict within a process.
+func lockFile(f *os.File, exclusive bool) error {
+	lk := unix.Flock_t{Type: unix.F_RDLCK, Whence: 0}
+	if exclusive {
+		lk.Type = unix.F_WRLCK
+	}
+
+	err := unix.FcntlFlock(f.Fd(), unix.F_SETLK, &lk)
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, unix.EAGAIN), errors.Is(err, unix.EACCES):
+		return ErrLocked
+	default:
+		return &os.PathError{Op: "fcntl", Path: f.Name(), Err: err}
+	}
+}
+
+func unlockFile(f *os.File) error {
+	lk := unix.Flock_t{Type: unix.F_UNLCK, Whence: 0}
+	if err := unix.FcntlFlock(f.Fd(), unix.F_SETLK, &lk); err != nil {
+		return &os.PathError{Op: "fcntl", Path: f.Name(), Err: err}
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package xdg
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestLockFileExclusive(t *testing.T) {
+	dirType := dirForTest(t.TempDir(), nil)
+
+	l1, err := TryLockFile(dirType, "my_app", "state", true)
+	if err != nil {
+		t.Fatalf("TryLockFile() error = %v", err)
+	}
+	if l1.StaleOwner != nil {
+		t.Errorf("TryLockFile() StaleOwner = %v, want nil", l1.StaleOwner)
+	}
+
+	if _, err := TryLockFile(dirType, "my_app", "state", false); !errors.Is(err, ErrLocked) {
+		t.Errorf("TryLockFile() error = %v, want %v", err, ErrLocked)
+	}
+
+	ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := LockFile(ctx, dirType, "my_app", "state", true); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("LockFile() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	// Wait for the lock to be released by another owner.
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		if err := l1.Unlock(); err != nil {
+			t.Errorf("Unlock() error = %v", err)
+		}
+	}()
+
+	l2, err := LockFile(t.Context(), dirType, "my_app", "state", true)
+	if err != nil {
+		t.Fatalf("LockFile() error = %v", err)
+	}
+	if l2.StaleOwner != nil {
+		t.Errorf("LockFile() StaleOwner = %v, want nil", l2.StaleOwner)
+	}
+	if err := l2.Unlock(); err != nil {
+		t.Errorf("Unlock() error = %v", err)
+	}
+	if err := l2.Unlock(); err == nil {
+		t.Error("second Unlock() error = nil, want error")
+	}
+}
+
+func TestLockFileShared(t *testing.T) {
+	dirType := dirForTest(t.TempDir(), nil)
+
+	l1, err := TryLockFile(dirType, "my_app", "state", false)
+	if err != nil {
+		t.Fatalf("TryLockFile() error = %v", err)
+	}
+	l2, err := TryLockFile(dirType, "my_app", "state", false)
+	if err != nil {
+		t.Fatalf("TryLockFile() error = %v", err)
+	}
+
+	if _, err := TryLockFile(dirType, "my_app", "state", true); !errors.Is(err, ErrLocked) {
+		t.Errorf("TryLockFile() error = %v, want %v", err, ErrLocked)
+	}
+
+	if err := errors.Join(l1.Unlock(), l2.Unlock()); err != nil {
+		t.Errorf("Unlock() error = %v", err)
+	}
+}
+
+func TestLockFileStaleOwner(t *testing.T) {
+	dirType := dirForTest(t.TempDir(), nil)
+
+	path, err := CreateFile(dirType, "my_app", "state"+lockFileSuffix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("12345\ncrashed-host\n"), NewFilePermissions); err != nil {
+		t.Fatal(err)
+	}
+
+	l, err := TryLockFile(dirType, "my_app", "state", true)
+	if err != nil {
+		t.Fatalf("TryLockFile() error = %v", err)
+	}
+	want := LockOwner{PID: 12345, Hostname: "crashed-host"}
+	if l.StaleOwner == nil || *l.StaleOwner != want {
+		t.Errorf("TryLockFile() StaleOwner = %v, want %v", l.StaleOwner, want)
+	}
+
+	f, err := os.Open(path) //gosec:disable G304
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if got := readLockOwner(f); got == nil || got.PID != os.Getpid() {
+		t.Errorf("readLockOwner() = %v, want PID %d", got, os.Getpid())
+	}
+
+	if err := l.Unlock(); err != nil {
+		t.Fatalf("Unlock() error = %v", err)
+	}
+	if got := readLockOwner(f); got != nil {
+		t.Errorf("readLockOwner() after Unlock() = %v, want nil", got)
+	}
+}
+
+func TestLockFileErrors(t *testing.T) {
+	dirType := dirForTest(t.TempDir(), nil)
+
+	if _, err := TryLockFile(dirType, "../other_app", "state", true); err == nil {
+		t.Error("TryLockFile() with insecure app name error = nil, want error")
+	}
+	if _, err := LockFile(t.Context(), dirType, "my_app", "../state", true); err == nil {
+		t.Error("LockFile() with insecure lock name error = nil, want error")
+	}
+}
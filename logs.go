@@ -0,0 +1,377 @@
+package xdg
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	logsSubdir        = "logs"
+	logFileSuffix     = ".log"
+	gzipSuffix        = ".gz"
+	logArchiveTimeFmt = "2006-01-02T15-04-05.000000000"
+)
+
+// LogOptions defines when a [LogWriter] rotates its log file,
+// and what it does with the rotated files. Zero values mean no limit.
+type LogOptions struct {
+	// MaxSize is the maximum size of the log file, in bytes. The file is rotated
+	// before a write that would exceed it, unless the file is empty.
+	MaxSize int64
+	// MaxAge is the maximum time since the log file was created.
+	// The file is rotated before the first write after that.
+	MaxAge time.Duration
+	// MaxArchives is the maximum number of rotated files to keep.
+	// Older ones are removed after each rotation.
+	MaxArchives int
+	// Compress determines whether rotated files are compressed with gzip.
+	Compress bool
+}
+
+// LogWriter is an [io.WriteCloser] which appends to an app's log file,
+// and rotates it according to its [LogOptions]. It was opened by [OpenLog].
+//
+// It's safe to use concurrently, even in different processes: writes and
+// rotations are serialized with an exclusive lock, and each write is appended
+// to the current log file, even if another process rotated it.
+type LogWriter struct {
+	// Path is the full path of the log file: "<StateHome>/<app>/logs/<name>.log".
+	Path string
+
+	opts  LogOptions
+	mu    sync.Mutex
+	f     *os.File
+	lockF *os.File
+}
+
+// OpenLog opens the given app's log file for appending, under [StateHome].
+// It creates the file and any parent directories if they don't exist yet.
+//
+// Rotated files are renamed to "<name>.<UTC timestamp>.log" (with a ".gz"
+// suffix if they're compressed), in the same directory.
+//
+// Note: this function normalizes the app and log names and ensures they
+// don't contain path elements, but the caller is responsible for input vetting.
+func OpenLog(appName, name string, opts LogOptions) (*LogWriter, error) {
+	return defaultResolver.OpenLog(appName, name, opts)
+}
+
+// OpenLog is like the package-level [OpenLog], but uses r's environment.
+func (r *Resolver) OpenLog(appName, name string, opts LogOptions) (*LogWriter, error) {
+	if err := r.checkHost(); err != nil {
+		return nil, err
+	}
+	return openLog(r.StateHome, appName, name, opts)
+}
+
+func openLog(dirType func() (string, error), appName, name string, opts LogOptions) (*LogWriter, error) {
+	name = filepath.Clean(name)
+	if name == "." {
+		return nil, errors.New("log name is empty")
+	}
+	if strings.Contains(name, pathSep) {
+		return nil, errors.New("log name must not contain separator")
+	}
+
+	// The lock file also records the creation time of the current log file.
+	lockPath, err := CreateFilePath(dirType, appName, filepath.Join(logsSubdir, name+lockFileSuffix))
+	if err != nil {
+		return nil, err
+	}
+
+	lockF, err := os.OpenFile(lockPath, os.O_RDWR, NewFilePermissions) //gosec:disable G304
+	if err != nil {
+		return nil, err
+	}
+
+	if err := waitLockFile(context.Background(), lockF, true); err != nil {
+		return nil, errors.Join(err, lockF.Close())
+	}
+
+	// This also records the creation time if it creates the log file.
+	w := &LogWriter{Path: filepath.Join(filepath.Dir(lockPath), name+logFileSuffix), opts: opts, lockF: lockF}
+	if err := errors.Join(w.reopenIfRotated(), unlockFile(lockF)); err != nil {
+		if w.f != nil {
+			err = errors.Join(err, w.f.Close())
+		}
+		return nil, errors.Join(err, lockF.Close())
+	}
+
+	return w, nil
+}
+
+// Write appends p to the log file, after rotating it if necessary.
+// If the rotation fails (e.g. in Windows, while another process has the file
+// open), it keeps appending to the current file, and retries on the next write.
+func (w *LogWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.lockF == nil {
+		return 0, os.ErrClosed
+	}
+
+	if err := waitLockFile(context.Background(), w.lockF, true); err != nil {
+		return 0, err
+	}
+	defer func() { _ = unlockFile(w.lockF) }()
+
+	if err := w.reopenIfRotated(); err != nil {
+		return 0, err
+	}
+
+	if w.shouldRotate(len(p)) {
+		if err := w.rotate(); err != nil {
+			if w.f == nil {
+				return 0, err // Can't keep appending.
+			}
+		}
+	}
+
+	return w.f.Write(p)
+}
+
+// Close closes the log file.
+func (w *LogWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.lockF == nil {
+		return os.ErrClosed
+	}
+
+	err := w.lockF.Close()
+	if w.f != nil { // Nil if a previous rotation failed to reopen it.
+		err = errors.Join(w.f.Close(), err)
+	}
+	w.f, w.lockF = nil, nil
+	return err
+}
+
+// reopenIfRotated opens the log file if it isn't open yet,
+// or reopens it if another process has rotated it.
+func (w *LogWriter) reopenIfRotated() error {
+	current, err := os.Stat(w.Path)
+	missing := errors.Is(err, fs.ErrNotExist)
+	if err == nil && w.f != nil {
+		if info, err := w.f.Stat(); err == nil && os.SameFile(info, current) {
+			return nil
+		}
+	} else if err != nil && !missing {
+		return err
+	}
+
+	f, err := openLogFile(w.Path)
+	if err != nil {
+		return err
+	}
+
+	if w.f != nil {
+		_ = w.f.Close()
+	}
+	w.f = f
+
+	if missing { // Removed, not rotated.
+		return w.recordCreatedTime(time.Now())
+	}
+	return nil
+}
+
+// shouldRotate checks whether the log file needs to be rotated before writing n bytes.
+func (w *LogWriter) shouldRotate(n int) bool {
+	info, err := w.f.Stat()
+	if err != nil || info.Size() == 0 {
+		return false
+	}
+
+	if w.opts.MaxSize > 0 && info.Size()+int64(n) > w.opts.MaxSize {
+		return true
+	}
+
+	if w.opts.MaxAge > 0 {
+		return time.Since(w.createdTime()) >= w.opts.MaxAge
+	}
+
+	return false
+}
+
+// createdTime returns the creation time of the current log file, which is
+// recorded in the lock file. If it's unknown (e.g. the log file was created
+// by an older version of this package), it's recorded as the current time.
+func (w *LogWriter) createdTime() time.Time {
+	data, err := io.ReadAll(io.NewSectionReader(w.lockF, 0, 1024))
+	if err == nil {
+		if t, err := time.Parse(time.RFC3339Nano, strings.TrimSpace(string(data))); err == nil {
+			return t
+		}
+	}
+
+	now := time.Now()
+	_ = w.recordCreatedTime(now)
+	return now
+}
+
+func (w *LogWriter) recordCreatedTime(t time.Time) error {
+	if err := w.lockF.Truncate(0); err != nil {
+		return err
+	}
+	_, err := w.lockF.WriteAt([]byte(t.UTC().Format(time.RFC3339Nano)+"\n"), 0)
+	return err
+}
+
+// rotate renames the log file, starts a new one, and then
+// compresses and removes archives according to the options.
+func (w *LogWriter) rotate() error {
+	now := time.Now()
+	dir, name := filepath.Split(w.Path)
+	base := strings.TrimSuffix(name, logFileSuffix)
+	archive := filepath.Join(dir, base+"."+now.UTC().Format(logArchiveTimeFmt)+logFileSuffix)
+
+	// Files can't be renamed in Windows while they're open.
+	if err := w.f.Close(); err != nil {
+		return err
+	}
+	w.f = nil
+
+	renameErr := os.Rename(w.Path, archive)
+
+	f, err := openLogFile(w.Path)
+	if err != nil {
+		return errors.Join(renameErr, err)
+	}
+	w.f = f
+
+	if renameErr != nil {
+		return renameErr
+	}
+
+	errs := []error{w.recordCreatedTime(now)}
+	if w.opts.Compress {
+		errs = append(errs, compressFile(archive))
+	}
+	if w.opts.MaxArchives > 0 {
+		errs = append(errs, removeOldLogArchives(dir, base, w.opts.MaxArchives))
+	}
+
+	return errors.Join(errs...)
+}
+
+func openLogFile(path string) (*os.File, error) {
+	return os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, NewFilePermissions) //gosec:disable G304
+}
+
+// compressFile replaces the given file with a gzip-compressed copy of it.
+func compressFile(path string) error {
+	dir, name := filepath.Split(path)
+	in, err := os.Open(path) //gosec:disable G304
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	root, err := os.OpenRoot(dir)
+	if err != nil {
+		return err
+	}
+	defer root.Close()
+
+	tempName := "." + name + gzipSuffix + ".tmp"
+	out, err := root.OpenFile(tempName, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, NewFilePermissions)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = root.Remove(tempName) }() // No-op after a successful rename.
+
+	zw := gzip.NewWriter(out)
+	zw.Name = name
+	if _, err := io.Copy(zw, in); err != nil {
+		_ = out.Close()
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		_ = out.Close()
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		_ = out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+
+	if err := root.Rename(tempName, name+gzipSuffix); err != nil {
+		return err
+	}
+
+	_ = in.Close()
+	return root.Remove(name)
+}
+
+// removeOldLogArchives removes the oldest rotated files of the given log,
+// so that no more than maxArchives are kept.
+func removeOldLogArchives(dir, base string, maxArchives int) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	var archives []string
+	for _, e := range entries {
+		if isLogArchive(e.Name(), base) {
+			archives = append(archives, e.Name())
+		}
+	}
+	if len(archives) <= maxArchives {
+		return nil
+	}
+
+	// Timestamps are sorted lexically in chronological order.
+	slices.SortFunc(archives, func(a, b string) int {
+		return strings.Compare(logArchiveTime(a, base), logArchiveTime(b, base))
+	})
+
+	var errs []error
+	for _, name := range archives[:len(archives)-maxArchives] {
+		if err := os.Remove(filepath.Join(dir, name)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// isLogArchive checks whether a file name is a rotated file of the given log,
+// and not the current or rotated file of another log with a similar name.
+func isLogArchive(name, base string) bool {
+	ts := logArchiveTime(name, base)
+	if ts == "" {
+		return false
+	}
+
+	_, err := time.Parse(logArchiveTimeFmt, ts)
+	return err == nil
+}
+
+// logArchiveTime returns the timestamp in the name of a rotated log file.
+func logArchiveTime(name, base string) string {
+	name = strings.TrimSuffix(name, gzipSuffix)
+	ts, ok := strings.CutPrefix(name, base+".")
+	if !ok {
+		return ""
+	}
+	ts, ok = strings.CutSuffix(ts, logFileSuffix)
+	if !ok {
+		return ""
+	}
+	return ts
+}
@@ -0,0 +1,208 @@
+package xdg
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLogWriterRotateBySize(t *testing.T) {
+	dir := t.TempDir()
+	w, err := openLog(dirForTest(dir, nil), "my_app", "app", LogOptions{MaxSize: 10, MaxArchives: 2, Compress: true})
+	if err != nil {
+		t.Fatalf("openLog() error = %v", err)
+	}
+	defer w.Close()
+
+	if want := filepath.Join(dir, "my_app", logsSubdir, "app.log"); w.Path != want {
+		t.Errorf("openLog() Path = %q, want %q", w.Path, want)
+	}
+
+	for _, line := range []string{"line 1\n", "line 2\n", "line 3\n", "line 4\n"} {
+		if _, err := io.WriteString(w, line); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+
+	got, err := os.ReadFile(w.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "line 4\n" {
+		t.Errorf("log file = %q, want %q", got, "line 4\n")
+	}
+
+	archives := logArchives(t, filepath.Dir(w.Path), "app")
+	if len(archives) != 2 {
+		t.Fatalf("archives = %q, want 2", archives)
+	}
+	for i, want := range []string{"line 2\n", "line 3\n"} {
+		if !strings.HasSuffix(archives[i], logFileSuffix+gzipSuffix) {
+			t.Errorf("archive %q is not compressed", archives[i])
+			continue
+		}
+		if got := readGzipFile(t, filepath.Join(filepath.Dir(w.Path), archives[i])); got != want {
+			t.Errorf("archive %q = %q, want %q", archives[i], got, want)
+		}
+	}
+}
+
+func TestLogWriterRotateByAge(t *testing.T) {
+	before := time.Now().Add(-time.Second)
+	w, err := openLog(dirForTest(t.TempDir(), nil), "my_app", "app", LogOptions{MaxAge: time.Hour})
+	if err != nil {
+		t.Fatalf("openLog() error = %v", err)
+	}
+	defer w.Close()
+
+	// The creation time is recorded as soon as the log file is created.
+	data, err := os.ReadFile(strings.TrimSuffix(w.Path, logFileSuffix) + lockFileSuffix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	created, err := time.Parse(time.RFC3339Nano, strings.TrimSpace(string(data)))
+	if err != nil || created.Before(before) {
+		t.Errorf("openLog() recorded creation time %q, want after %v", data, before)
+	}
+
+	if _, err := io.WriteString(w, "old\n"); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if _, err := io.WriteString(w, "still old\n"); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if archives := logArchives(t, filepath.Dir(w.Path), "app"); len(archives) != 0 {
+		t.Fatalf("archives before MaxAge = %q, want none", archives)
+	}
+
+	if err := w.recordCreatedTime(time.Now().Add(-2 * time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.WriteString(w, "new\n"); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	if archives := logArchives(t, filepath.Dir(w.Path), "app"); len(archives) != 1 {
+		t.Errorf("archives after MaxAge = %q, want 1", archives)
+	}
+	if got, _ := os.ReadFile(w.Path); string(got) != "new\n" {
+		t.Errorf("log file = %q, want %q", got, "new\n")
+	}
+}
+
+func TestLogWriterMultipleWriters(t *testing.T) {
+	dirType := dirForTest(t.TempDir(), nil)
+	w1, err := openLog(dirType, "my_app", "app", LogOptions{MaxSize: 12})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w1.Close()
+	w2, err := openLog(dirType, "my_app", "app", LogOptions{MaxSize: 12})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w2.Close()
+
+	for _, step := range []struct {
+		w    *LogWriter
+		line string
+	}{
+		{w1, "w1 a\n"},
+		{w2, "w2 b\n"},
+		{w1, "w1 c\n"}, // Rotated by w1.
+		{w2, "w2 d\n"}, // Appended to the new file by w2.
+	} {
+		if _, err := io.WriteString(step.w, step.line); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+
+	got, err := os.ReadFile(w1.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "w1 c\nw2 d\n"; string(got) != want {
+		t.Errorf("log file = %q, want %q", got, want)
+	}
+}
+
+func TestLogWriterClosed(t *testing.T) {
+	w, err := openLog(dirForTest(t.TempDir(), nil), "my_app", "app", LogOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if _, err := w.Write([]byte("data")); err == nil {
+		t.Error("Write() after Close() error = nil, want error")
+	}
+}
+
+func TestIsLogArchive(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		want bool
+	}{
+		{name: "archive", file: "app.2026-10-18T12-00-00.000000000.log", want: true},
+		{name: "compressed", file: "app.2026-10-18T12-00-00.000000000.log.gz", want: true},
+		{name: "current", file: "app.log"},
+		{name: "other_log", file: "app.debug.log"},
+		{name: "other_archive", file: "app.debug.2026-10-18T12-00-00.000000000.log"},
+		{name: "lock", file: "app.lock"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isLogArchive(tt.file, "app"); got != tt.want {
+				t.Errorf("isLogArchive() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func logArchives(t *testing.T, dir, base string) []string {
+	t.Helper()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var archives []string
+	for _, e := range entries {
+		if isLogArchive(e.Name(), base) {
+			archives = append(archives, e.Name())
+		}
+	}
+
+	slices.Sort(archives)
+	return archives
+}
+
+func readGzipFile(t *testing.T, path string) string {
+	t.Helper()
+
+	f, err := os.Open(path) //gosec:disable G304
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
@@ -0,0 +1,82 @@
+package xdg
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+)
+
+// ExclusionMarker is a file that tells backup and archiving tools to exclude
+// the directory that contains it. See [WithExclusionMarkers].
+type ExclusionMarker struct {
+	// Name is the file name, e.g. "CACHEDIR.TAG".
+	Name string
+	// Content is the file's content. It may be empty.
+	Content []byte
+}
+
+// CacheDirTag is a Cache Directory Tag (https://bford.info/cachedir/), which is
+// recognized by tools such as restic, Borg, and "tar --exclude-caches".
+var CacheDirTag = ExclusionMarker{
+	Name: "CACHEDIR.TAG",
+	Content: []byte("Signature: 8a477f597d28d172789f06886806bc55\n" +
+		"# This file is a cache directory tag.\n" +
+		"# For information about cache directory tags, see:\n" +
+		"#\thttps://bford.info/cachedir/\n"),
+}
+
+// NoBackupMarker is an empty ".nobackup" file, which is a common convention
+// for tools that support exclusion by the presence of a file (e.g. Borg's
+// "--exclude-if-present", and restic's "--exclude-if-present").
+var NoBackupMarker = ExclusionMarker{Name: ".nobackup"}
+
+// CreateOption is an optional parameter of [CreateDir] and [CreateSubdir].
+type CreateOption func(*createOptions)
+
+type createOptions struct {
+	markers []ExclusionMarker
+}
+
+// WithExclusionMarkers writes the given marker files (typically [CacheDirTag])
+// in newly-created directories, to exclude them from backups. This is intended
+// for directories under [CacheHome], e.g.:
+//
+//	xdg.CreateDir(xdg.CacheHome, "my_app", xdg.WithExclusionMarkers(xdg.CacheDirTag))
+//
+// [TrimCache] never removes the built-in markers ([CacheDirTag] and [NoBackupMarker]).
+func WithExclusionMarkers(markers ...ExclusionMarker) CreateOption {
+	return func(o *createOptions) {
+		o.markers = append(o.markers, markers...)
+	}
+}
+
+func newCreateOptions(opts []CreateOption) createOptions {
+	var o createOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// writeMarkers writes all the exclusion markers in the given directory.
+func (o createOptions) writeMarkers(dir string) error {
+	for _, m := range o.markers {
+		name := filepath.Clean(m.Name)
+		if name == "." {
+			return errors.New("exclusion marker name is empty")
+		}
+		if strings.Contains(name, pathSep) {
+			return errors.New("exclusion marker name must not contain separator")
+		}
+
+		if err := writeFileAtomic(dir, name, m.Content, NewFilePermissions); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isExclusionMarker checks whether a file name is one of the built-in markers.
+func isExclusionMarker(name string) bool {
+	return name == CacheDirTag.Name || name == NoBackupMarker.Name
+}
@@ -0,0 +1,93 @@
+package xdg
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCreateDirWithExclusionMarkers(t *testing.T) {
+	dir := t.TempDir()
+	opt := WithExclusionMarkers(CacheDirTag, NoBackupMarker)
+
+	path, err := CreateDir(dirForTest(dir, nil), "my_app", opt)
+	if err != nil {
+		t.Fatalf("CreateDir() error = %v", err)
+	}
+
+	got, err := os.ReadFile(filepath.Join(path, CacheDirTag.Name))
+	if err != nil {
+		t.Fatalf("CreateDir() did not write %s: %v", CacheDirTag.Name, err)
+	}
+	if !strings.HasPrefix(string(got), "Signature: 8a477f597d28d172789f06886806bc55") {
+		t.Errorf("%s content = %q, want spec signature", CacheDirTag.Name, got)
+	}
+	if _, err := os.Stat(filepath.Join(path, NoBackupMarker.Name)); err != nil {
+		t.Errorf("CreateDir() did not write %s: %v", NoBackupMarker.Name, err)
+	}
+
+	// Existing directories are not modified.
+	if err := os.Remove(filepath.Join(path, CacheDirTag.Name)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := CreateDir(dirForTest(dir, nil), "my_app", opt); err != nil {
+		t.Fatalf("CreateDir() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(path, CacheDirTag.Name)); !os.IsNotExist(err) {
+		t.Errorf("CreateDir() modified an existing directory: %v", err)
+	}
+}
+
+func TestCreateSubdirWithExclusionMarkers(t *testing.T) {
+	tests := []struct {
+		name         string
+		appDirExists bool
+		wantTagIn    string
+	}{
+		{
+			name:      "new_app_dir",
+			wantTagIn: "my_app",
+		},
+		{
+			name:         "existing_app_dir",
+			appDirExists: true,
+			wantTagIn:    "my_app/sub",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if tt.appDirExists {
+				if err := os.Mkdir(filepath.Join(dir, "my_app"), NewDirectoryPermissions); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			marker := ExclusionMarker{Name: "custom", Content: []byte("data")}
+			opt := WithExclusionMarkers(marker)
+			if _, err := CreateSubdir(dirForTest(dir, nil), "my_app", "sub/dir", opt); err != nil {
+				t.Fatalf("CreateSubdir() error = %v", err)
+			}
+
+			for _, d := range []string{"my_app", "my_app/sub", "my_app/sub/dir"} {
+				got, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(d), marker.Name))
+				if want := d == tt.wantTagIn; (err == nil) != want {
+					t.Errorf("marker in %q = %v, want %v", d, err == nil, want)
+				}
+				if err == nil && !bytes.Equal(got, marker.Content) {
+					t.Errorf("marker content in %q = %q, want %q", d, got, marker.Content)
+				}
+			}
+		})
+	}
+}
+
+func TestCreateDirWithInvalidExclusionMarker(t *testing.T) {
+	opt := WithExclusionMarkers(ExclusionMarker{Name: "../escape"})
+	if _, err := CreateDir(tempDir(t), "my_app", opt); err == nil {
+		t.Error("CreateDir() error = nil, want error")
+	}
+}
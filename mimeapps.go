@@ -0,0 +1,250 @@
+package xdg
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+const (
+	mimeAppsFileName     = "mimeapps.list"
+	mimeDefaultsGroup    = "Default Applications"
+	mimeAddedGroup       = "Added Associations"
+	mimeRemovedGroup     = "Removed Associations"
+	mimeTypeInvalidChars = " \t\n\r=[];"
+)
+
+// DefaultApplication returns the desktop file ID (e.g. "org.example.App.desktop")
+// of the default application for the given MIME type (e.g. "text/plain"), according
+// to the FreeDesktop.org MIME Applications Associations specification
+// (https://specifications.freedesktop.org/mime-apps-spec/).
+//
+// It's the first installed application in the "Default Applications" group of
+// the "mimeapps.list" files (see [MIMEApplications] for their locations and order),
+// or else the first associated application. If there isn't any, this function
+// returns an empty string but no error. An application is installed if its
+// desktop file can be found with [FindDesktopEntry].
+func DefaultApplication(mimeType string) (string, error) {
+	return defaultResolver.DefaultApplication(mimeType)
+}
+
+// DefaultApplication is like the package-level [DefaultApplication], but uses r's environment.
+func (r *Resolver) DefaultApplication(mimeType string) (string, error) {
+	ids, err := r.MIMEApplications(mimeType)
+	if err != nil || len(ids) == 0 {
+		return "", err
+	}
+	return ids[0], nil
+}
+
+// MIMEApplications returns the desktop file IDs of the installed applications
+// which are associated with the given MIME type, in order of preference:
+// the default application (see [DefaultApplication]) first, and then the
+// applications in the "Added Associations" groups of the "mimeapps.list" files,
+// except those listed in the "Removed Associations" group of the same or
+// a more important file. Implicit associations, based on the MimeType key of
+// desktop files, are not included.
+//
+// The files are, in order of importance: "$desktop-mimeapps.list" (for each
+// desktop environment in $XDG_CURRENT_DESKTOP, in lowercase) and then "mimeapps.list",
+// in [ConfigHome], each of [ConfigDirs], the "applications" subdirectory of [DataHome]
+// (deprecated), and the "applications" subdirectory of each of [DataDirs].
+func MIMEApplications(mimeType string) ([]string, error) {
+	return defaultResolver.MIMEApplications(mimeType)
+}
+
+// MIMEApplications is like the package-level [MIMEApplications], but uses r's environment.
+func (r *Resolver) MIMEApplications(mimeType string) ([]string, error) {
+	if err := r.checkHost(); err != nil {
+		return nil, err
+	}
+	if err := checkMIMEType(mimeType); err != nil {
+		return nil, err
+	}
+
+	files, err := r.mimeAppsFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	var defaults, added []string
+	removed := map[string]bool{}
+	for _, path := range files {
+		kf, err := readMIMEAppsFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if kf == nil {
+			continue
+		}
+
+		v, _ := kf.group(mimeDefaultsGroup).value(mimeType)
+		defaults = append(defaults, splitList(v)...)
+
+		// Removals in a file also apply to additions in the same file.
+		v, _ = kf.group(mimeRemovedGroup).value(mimeType)
+		for _, id := range splitList(v) {
+			removed[id] = true
+		}
+
+		v, _ = kf.group(mimeAddedGroup).value(mimeType)
+		for _, id := range splitList(v) {
+			if !removed[id] && !slices.Contains(added, id) {
+				added = append(added, id)
+			}
+		}
+	}
+
+	dataPaths, err := searchPaths(r.DataHome, r.DataDirs)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []string
+	if i := slices.IndexFunc(defaults, func(id string) bool {
+		return desktopFileInstalled(dataPaths, id)
+	}); i >= 0 {
+		ids = append(ids, defaults[i])
+	}
+
+	for _, id := range added {
+		if !slices.Contains(ids, id) && desktopFileInstalled(dataPaths, id) {
+			ids = append(ids, id)
+		}
+	}
+
+	return ids, nil
+}
+
+// SetDefaultApplication sets the default application for the given MIME
+// type (e.g. "text/plain") in the "mimeapps.list" file in [ConfigHome],
+// atomically. The desktop file ID (e.g. "org.example.App.desktop") is also
+// added as the first entry in the "Added Associations" group of that file,
+// and removed from its "Removed Associations" group, if it's there.
+//
+// Other groups and keys in the file are preserved, but comments are not.
+// This function doesn't check that the application is installed.
+func SetDefaultApplication(mimeType, id string) error {
+	return defaultResolver.SetDefaultApplication(mimeType, id)
+}
+
+// SetDefaultApplication is like the package-level [SetDefaultApplication], but uses r's environment.
+func (r *Resolver) SetDefaultApplication(mimeType, id string) error {
+	if err := r.checkHost(); err != nil {
+		return err
+	}
+	if err := checkMIMEType(mimeType); err != nil {
+		return err
+	}
+	if err := checkDesktopFileID(id); err != nil {
+		return err
+	}
+
+	dir, err := r.ConfigHome()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, NewDirectoryPermissions); err != nil {
+		return err
+	}
+
+	kf, err := readMIMEAppsFile(filepath.Join(dir, mimeAppsFileName))
+	if err != nil {
+		return err
+	}
+	if kf == nil {
+		kf = &keyFile{}
+	}
+
+	kf.addGroup(mimeDefaultsGroup).set(mimeType, joinList([]string{id}))
+
+	g := kf.addGroup(mimeAddedGroup)
+	v, _ := g.value(mimeType)
+	ids := slices.DeleteFunc(splitList(v), func(s string) bool { return s == id })
+	g.set(mimeType, joinList(append([]string{id}, ids...)))
+
+	if g := kf.group(mimeRemovedGroup); g != nil {
+		if v, ok := g.value(mimeType); ok {
+			ids := slices.DeleteFunc(splitList(v), func(s string) bool { return s == id })
+			g.set(mimeType, joinList(ids))
+		}
+	}
+
+	return writeFileAtomic(dir, mimeAppsFileName, kf.bytes(), NewFilePermissions)
+}
+
+// mimeAppsFiles returns the paths of all the possible "mimeapps.list"
+// files, in order of importance. They don't necessarily exist.
+func (r *Resolver) mimeAppsFiles() ([]string, error) {
+	configPaths, err := searchPaths(r.ConfigHome, r.ConfigDirs)
+	if err != nil {
+		return nil, err
+	}
+
+	dataPaths, err := searchPaths(r.DataHome, r.DataDirs)
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range dataPaths {
+		configPaths = append(configPaths, filepath.Join(path, applicationsSubdir))
+	}
+
+	desktops := r.currentDesktops()
+	var files []string
+	for _, path := range configPaths {
+		for _, d := range desktops {
+			files = append(files, filepath.Join(path, strings.ToLower(d)+"-"+mimeAppsFileName))
+		}
+		files = append(files, filepath.Join(path, mimeAppsFileName))
+	}
+
+	return files, nil
+}
+
+// readMIMEAppsFile reads and parses a "mimeapps.list" file.
+// It returns nil but no error if the file doesn't exist.
+func readMIMEAppsFile(path string) (*keyFile, error) {
+	data, err := os.ReadFile(path) //gosec:disable G304
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	kf, err := parseKeyFile(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %q: %w", path, err)
+	}
+	return kf, nil
+}
+
+// desktopFileInstalled checks whether a desktop file with the given ID exists
+// in the "applications" subdirectory of any of the given data directories.
+func desktopFileInstalled(dataPaths []string, id string) bool {
+	if checkDesktopFileID(id) != nil {
+		return false
+	}
+
+	for _, path := range dataPaths {
+		if findDesktopFileByID(filepath.Join(path, applicationsSubdir), id) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// checkMIMEType ensures that a MIME type is in the form "type/subtype",
+// and can be used as a key in a "mimeapps.list" file.
+func checkMIMEType(mimeType string) error {
+	typ, subtype, _ := strings.Cut(mimeType, "/")
+	if typ == "" || subtype == "" || strings.Count(mimeType, "/") != 1 ||
+		strings.ContainsAny(mimeType, mimeTypeInvalidChars) {
+		return fmt.Errorf("invalid MIME type %q", mimeType)
+	}
+	return nil
+}
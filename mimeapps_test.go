@@ -0,0 +1,169 @@
+package xdg
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestMIMEApplications(t *testing.T) {
+	dir := t.TempDir()
+	cfgHome := filepath.Join(dir, "config")
+	cfgDir := filepath.Join(dir, "etc")
+	dataHome := filepath.Join(dir, "data")
+	dataDir := filepath.Join(dir, "usr")
+	r := &Resolver{Getenv: mapEnv(map[string]string{
+		"XDG_CONFIG_HOME":     cfgHome,
+		"XDG_CONFIG_DIRS":     cfgDir,
+		"XDG_DATA_HOME":       dataHome,
+		"XDG_DATA_DIRS":       dataDir,
+		"XDG_CURRENT_DESKTOP": "GNOME",
+	})}
+
+	files := map[string]string{
+		filepath.Join(cfgHome, "gnome-mimeapps.list"): "[Default Applications]\ntext/html=missing.desktop;\n",
+		filepath.Join(cfgHome, "mimeapps.list"): "[Default Applications]\ntext/plain=editor.desktop;\n" +
+			"[Removed Associations]\ntext/plain=vendor.desktop;\n",
+		filepath.Join(cfgDir, "mimeapps.list"): "[Default Applications]\n" +
+			"text/plain=vendor.desktop;\ntext/html=browser.desktop;\n" +
+			"[Added Associations]\ntext/plain=viewer.desktop;vendor.desktop;missing.desktop;\n" +
+			"text/html=editor.desktop;\n",
+		filepath.Join(dataDir, "applications", "mimeapps.list"): "[Added Associations]\n" +
+			"text/plain=editor.desktop;other-app.desktop;\n",
+	}
+	ids := []string{"editor.desktop", "vendor.desktop", "viewer.desktop", "browser.desktop", "other/app.desktop"}
+	for _, id := range ids {
+		files[filepath.Join(dataDir, "applications", id)] = "[Desktop Entry]\nType=Application\nName=App\n"
+	}
+	for path, content := range files {
+		if err := os.MkdirAll(filepath.Dir(path), NewDirectoryPermissions); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), NewFilePermissions); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name        string
+		mimeType    string
+		want        []string
+		wantDefault string
+		wantErr     bool
+	}{
+		{
+			name:        "merged",
+			mimeType:    "text/plain",
+			want:        []string{"editor.desktop", "viewer.desktop", "other-app.desktop"},
+			wantDefault: "editor.desktop",
+		},
+		{
+			name:        "desktop_specific_default_not_installed",
+			mimeType:    "text/html",
+			want:        []string{"browser.desktop", "editor.desktop"},
+			wantDefault: "browser.desktop",
+		},
+		{
+			name:     "unknown",
+			mimeType: "image/png",
+		},
+		{
+			name:     "invalid",
+			mimeType: "text",
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := r.MIMEApplications(tt.mimeType)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("MIMEApplications() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("MIMEApplications() = %q, want %q", got, tt.want)
+			}
+
+			def, err := r.DefaultApplication(tt.mimeType)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DefaultApplication() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if def != tt.wantDefault {
+				t.Errorf("DefaultApplication() = %q, want %q", def, tt.wantDefault)
+			}
+		})
+	}
+}
+
+func TestSetDefaultApplication(t *testing.T) {
+	dir := t.TempDir()
+	cfgHome := filepath.Join(dir, "config")
+	dataHome := filepath.Join(dir, "data")
+	r := &Resolver{Getenv: mapEnv(map[string]string{
+		"XDG_CONFIG_HOME": cfgHome,
+		"XDG_CONFIG_DIRS": filepath.Join(dir, "etc"),
+		"XDG_DATA_HOME":   dataHome,
+		"XDG_DATA_DIRS":   filepath.Join(dir, "usr"),
+	})}
+
+	for _, id := range []string{"editor.desktop", "viewer.desktop"} {
+		path := filepath.Join(dataHome, "applications", id)
+		if err := os.MkdirAll(filepath.Dir(path), NewDirectoryPermissions); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("[Desktop Entry]\nName=App\n"), NewFilePermissions); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := r.SetDefaultApplication("text/plain", "viewer.desktop"); err != nil {
+		t.Fatalf("SetDefaultApplication() error = %v", err)
+	}
+
+	path := filepath.Join(cfgHome, "mimeapps.list")
+	content := "# Comment\n[Removed Associations]\ntext/plain=editor.desktop;other.desktop;\n\n" +
+		"[Added Associations]\ntext/plain=viewer.desktop;\n\n[X-Custom]\nkey=value\n"
+	if err := os.WriteFile(path, []byte(content), NewFilePermissions); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.SetDefaultApplication("text/plain", "editor.desktop"); err != nil {
+		t.Fatalf("SetDefaultApplication() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "[Removed Associations]\ntext/plain=other.desktop;\n\n" +
+		"[Added Associations]\ntext/plain=editor.desktop;viewer.desktop;\n\n[X-Custom]\nkey=value\n\n" +
+		"[Default Applications]\ntext/plain=editor.desktop;\n"
+	if string(data) != want {
+		t.Errorf("mimeapps.list = %q, want %q", data, want)
+	}
+
+	got, err := r.MIMEApplications("text/plain")
+	if err != nil {
+		t.Fatalf("MIMEApplications() error = %v", err)
+	}
+	if want := []string{"editor.desktop", "viewer.desktop"}; !slices.Equal(got, want) {
+		t.Errorf("MIMEApplications() = %q, want %q", got, want)
+	}
+
+	invalid := [][2]string{{"text", "editor.desktop"}, {"text/plain", "editor"}, {"text/plain", "../editor.desktop"}}
+	for _, tt := range invalid {
+		if err := r.SetDefaultApplication(tt[0], tt[1]); err == nil {
+			t.Errorf("SetDefaultApplication(%q, %q) error = nil, want error", tt[0], tt[1])
+		}
+	}
+
+	if err := os.WriteFile(path, []byte("invalid line\n"), NewFilePermissions); err != nil {
+		t.Fatal(err)
+	}
+	err = r.SetDefaultApplication("text/plain", "editor.desktop")
+	if err == nil || !strings.Contains(err.Error(), "mimeapps.list") {
+		t.Errorf("SetDefaultApplication() with invalid file error = %v, want parse error", err)
+	}
+}
@@ -0,0 +1,614 @@
+package xdg
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	mimeSubdir       = "mime"
+	mimeMagicHeader  = "MIME-Magic\x00\n"
+	mimeNoGlobs      = "__NOGLOBS__"
+	mimeNoMagic      = "__NOMAGIC__"
+	mimeTextSniffLen = 128
+
+	mimeTypeOctetStream = "application/octet-stream"
+	mimeTypeTextPlain   = "text/plain"
+	mimeTypeDirectory   = "inode/directory"
+)
+
+var hostLittleEndian = binary.NativeEndian.Uint16([]byte{1, 0}) == 1
+
+// MIMEDatabase is a loaded FreeDesktop.org shared MIME-info database
+// (https://specifications.freedesktop.org/shared-mime-info-spec/), which
+// detects the MIME types of files the same way as desktop environments.
+// It was loaded by [LoadMIMEDatabase], and it's safe to use concurrently.
+type MIMEDatabase struct {
+	globs     []mimeGlob
+	magic     []mimeMagicRule
+	magicSize int
+	aliases   map[string]string
+	parents   map[string][]string
+}
+
+type mimeGlob struct {
+	weight        int
+	mimeType      string
+	pattern       string
+	caseSensitive bool
+}
+
+type mimeMagicRule struct {
+	priority  int
+	mimeType  string
+	matchlets []*mimeMatchlet
+	// noMagic means that the rules of this type in less important directories are removed.
+	noMagic bool
+}
+
+type mimeMatchlet struct {
+	offset   int
+	rangeLen int
+	value    []byte
+	mask     []byte
+	children []*mimeMatchlet
+}
+
+// LoadMIMEDatabase loads the "globs2", "magic", "aliases" and "subclasses" files
+// from the "mime" subdirectory of [DataHome] and each of [DataDirs], which are
+// generated by the update-mime-database tool. Missing files are ignored. Entries
+// in more important directories take precedence over less important ones, and
+// "__NOGLOBS__" and "__NOMAGIC__" entries remove the globs and magic rules of a
+// type from less important directories, respectively.
+func LoadMIMEDatabase() (*MIMEDatabase, error) {
+	return defaultResolver.LoadMIMEDatabase()
+}
+
+// LoadMIMEDatabase is like the package-level [LoadMIMEDatabase], but uses r's environment.
+func (r *Resolver) LoadMIMEDatabase() (*MIMEDatabase, error) {
+	if err := r.checkHost(); err != nil {
+		return nil, err
+	}
+
+	paths, err := searchPaths(r.DataHome, r.DataDirs)
+	if err != nil {
+		return nil, err
+	}
+
+	db := &MIMEDatabase{aliases: map[string]string{}, parents: map[string][]string{}}
+	for _, path := range slices.Backward(paths) {
+		if err := db.load(filepath.Join(path, mimeSubdir)); err != nil {
+			return nil, err
+		}
+	}
+
+	slices.SortStableFunc(db.magic, func(a, b mimeMagicRule) int {
+		return b.priority - a.priority
+	})
+	return db, nil
+}
+
+// load loads the database files in a single directory, which
+// is more important than all the previously-loaded ones.
+func (db *MIMEDatabase) load(dir string) error {
+	if data, err := readMIMEFile(dir, "globs2"); err != nil {
+		return err
+	} else if data != nil {
+		if err := db.loadGlobs(data); err != nil {
+			return fmt.Errorf("failed to parse %q: %w", filepath.Join(dir, "globs2"), err)
+		}
+	}
+
+	if data, err := readMIMEFile(dir, "magic"); err != nil {
+		return err
+	} else if data != nil {
+		rules, err := parseMIMEMagic(data)
+		if err != nil {
+			return fmt.Errorf("failed to parse %q: %w", filepath.Join(dir, "magic"), err)
+		}
+		for _, rule := range rules {
+			if rule.noMagic {
+				db.magic = slices.DeleteFunc(db.magic, func(old mimeMagicRule) bool {
+					return old.mimeType == rule.mimeType
+				})
+			}
+		}
+		rules = slices.DeleteFunc(rules, func(rule mimeMagicRule) bool {
+			return len(rule.matchlets) == 0
+		})
+
+		db.magic = append(rules, db.magic...)
+		for _, rule := range rules {
+			for _, m := range rule.matchlets {
+				db.magicSize = max(db.magicSize, m.extent())
+			}
+		}
+	}
+
+	if data, err := readMIMEFile(dir, "aliases"); err != nil {
+		return err
+	} else if data != nil {
+		err := parseMIMEPairs(data, func(alias, mimeType string) {
+			db.aliases[alias] = mimeType
+		})
+		if err != nil {
+			return fmt.Errorf("failed to parse %q: %w", filepath.Join(dir, "aliases"), err)
+		}
+	}
+
+	if data, err := readMIMEFile(dir, "subclasses"); err != nil {
+		return err
+	} else if data != nil {
+		err := parseMIMEPairs(data, func(mimeType, parent string) {
+			if !slices.Contains(db.parents[mimeType], parent) {
+				db.parents[mimeType] = append(db.parents[mimeType], parent)
+			}
+		})
+		if err != nil {
+			return fmt.Errorf("failed to parse %q: %w", filepath.Join(dir, "subclasses"), err)
+		}
+	}
+
+	return nil
+}
+
+// readMIMEFile reads a database file. It returns nil but no error if the file doesn't exist.
+func readMIMEFile(dir, name string) ([]byte, error) {
+	data, err := os.ReadFile(filepath.Join(dir, name)) //gosec:disable G304
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	return data, err
+}
+
+// loadGlobs parses the content of a "globs2" file, where each line
+// is in the form "weight:type:pattern[:flags]", and adds its globs
+// before the ones which were loaded from less important directories.
+func (db *MIMEDatabase) loadGlobs(data []byte) error {
+	var globs []mimeGlob
+	s := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; s.Scan(); n++ {
+		line := s.Text()
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.SplitN(line, ":", 4)
+		if len(fields) < 3 || fields[1] == "" || fields[2] == "" {
+			return fmt.Errorf("line %d: invalid glob %q", n, line)
+		}
+
+		weight, err := strconv.Atoi(fields[0])
+		if err != nil {
+			return fmt.Errorf("line %d: invalid weight %q", n, fields[0])
+		}
+
+		g := mimeGlob{weight: weight, mimeType: fields[1], pattern: fields[2]}
+		if g.pattern == mimeNoGlobs {
+			db.globs = slices.DeleteFunc(db.globs, func(old mimeGlob) bool {
+				return old.mimeType == g.mimeType
+			})
+			continue
+		}
+
+		if len(fields) == 4 {
+			flags, _, _ := strings.Cut(fields[3], ":")
+			g.caseSensitive = slices.Contains(strings.Split(flags, ","), "cs")
+		}
+		if !g.caseSensitive {
+			g.pattern = strings.ToLower(g.pattern)
+		}
+		globs = append(globs, g)
+	}
+
+	if err := s.Err(); err != nil {
+		return err
+	}
+
+	db.globs = append(globs, db.globs...)
+	return nil
+}
+
+// parseMIMEPairs parses the content of an "aliases" or "subclasses"
+// file, where each line contains two space-separated MIME types.
+func parseMIMEPairs(data []byte, add func(string, string)) error {
+	s := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; s.Scan(); n++ {
+		line := s.Text()
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return fmt.Errorf("line %d: invalid entry %q", n, line)
+		}
+		add(fields[0], fields[1])
+	}
+	return s.Err()
+}
+
+// parseMIMEMagic parses the content of a binary "magic" file. Each section starts
+// with a "[priority:type]" line, followed by matchlet lines in the form
+// "[indent]>offset=<2-byte value length><value>[&mask][~word-size][+range-length]".
+// A top-level "__NOMAGIC__" matchlet marks the section's type as [mimeMagicRule.noMagic].
+func parseMIMEMagic(data []byte) ([]mimeMagicRule, error) {
+	rest, ok := bytes.CutPrefix(data, []byte(mimeMagicHeader))
+	if !ok {
+		return nil, errors.New("invalid magic file header")
+	}
+
+	var rules []mimeMagicRule
+	var stack []*mimeMatchlet
+	for len(rest) > 0 {
+		if rest[0] == '[' {
+			line, after, ok := bytes.Cut(rest[1:], []byte("\n"))
+			header, found := bytes.CutSuffix(line, []byte("]"))
+			priority, mimeType, valid := strings.Cut(string(header), ":")
+			p, err := strconv.Atoi(priority)
+			if !ok || !found || !valid || err != nil || mimeType == "" {
+				return nil, fmt.Errorf("invalid section header %q", line)
+			}
+
+			rules = append(rules, mimeMagicRule{priority: p, mimeType: mimeType})
+			stack, rest = nil, after
+			continue
+		}
+
+		if len(rules) == 0 {
+			return nil, errors.New("matchlet before the first section")
+		}
+
+		m, indent, after, err := parseMIMEMatchlet(rest)
+		if err != nil {
+			return nil, fmt.Errorf("section %q: %w", rules[len(rules)-1].mimeType, err)
+		}
+		rest = after
+
+		switch {
+		case indent == 0 && string(m.value) == mimeNoMagic:
+			rules[len(rules)-1].noMagic = true
+			stack = nil
+			continue
+		case indent == 0:
+			rule := &rules[len(rules)-1]
+			rule.matchlets = append(rule.matchlets, m)
+		case indent <= len(stack):
+			parent := stack[indent-1]
+			parent.children = append(parent.children, m)
+		default:
+			return nil, fmt.Errorf("section %q: invalid matchlet indent %d", rules[len(rules)-1].mimeType, indent)
+		}
+		stack = append(stack[:indent], m)
+	}
+
+	return rules, nil
+}
+
+// parseMIMEMatchlet parses a single matchlet line, and returns
+// it, its indent level, and the rest of the data after it.
+func parseMIMEMatchlet(data []byte) (*mimeMatchlet, int, []byte, error) {
+	number := func(term byte) (int, error) {
+		i := bytes.IndexByte(data, term)
+		if i < 0 {
+			return 0, fmt.Errorf("missing %q in matchlet", term)
+		}
+		n, err := strconv.Atoi(string(data[:i]))
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid number %q in matchlet", data[:i])
+		}
+		data = data[i+1:]
+		return n, nil
+	}
+
+	indent := 0
+	if data[0] != '>' {
+		var err error
+		if indent, err = number('>'); err != nil {
+			return nil, 0, nil, err
+		}
+	} else {
+		data = data[1:]
+	}
+
+	offset, err := number('=')
+	if err != nil {
+		return nil, 0, nil, err
+	}
+
+	if len(data) < 2 {
+		return nil, 0, nil, errors.New("truncated matchlet")
+	}
+	n := int(binary.BigEndian.Uint16(data))
+	if len(data) < 2+n {
+		return nil, 0, nil, errors.New("truncated matchlet value")
+	}
+	m := &mimeMatchlet{offset: offset, rangeLen: 1, value: data[2 : 2+n]}
+	data = data[2+n:]
+
+	wordSize := 1
+	for len(data) > 0 && data[0] != '\n' {
+		switch c := data[0]; c {
+		case '&':
+			if len(data) < 1+n {
+				return nil, 0, nil, errors.New("truncated matchlet mask")
+			}
+			m.mask = data[1 : 1+n]
+			data = data[1+n:]
+		case '~', '+':
+			data = data[1:]
+			i := bytes.IndexAny(data, "~+\n")
+			if i < 0 {
+				return nil, 0, nil, errors.New("truncated matchlet")
+			}
+			v, err := strconv.Atoi(string(data[:i]))
+			if err != nil || v < 1 {
+				return nil, 0, nil, fmt.Errorf("invalid number %q in matchlet", data[:i])
+			}
+			if c == '~' {
+				wordSize = v
+			} else {
+				m.rangeLen = v
+			}
+			data = data[i:]
+		default:
+			return nil, 0, nil, fmt.Errorf("invalid character %q in matchlet", c)
+		}
+	}
+
+	if len(data) == 0 {
+		return nil, 0, nil, errors.New("unterminated matchlet")
+	}
+
+	// Multi-byte values are stored in big-endian byte order.
+	if wordSize > 1 && hostLittleEndian {
+		m.value = swapWords(m.value, wordSize)
+		if m.mask != nil {
+			m.mask = swapWords(m.mask, wordSize)
+		}
+	}
+
+	return m, indent, data[1:], nil
+}
+
+// swapWords returns a copy of b, with the byte order of each word of the given size reversed.
+func swapWords(b []byte, size int) []byte {
+	b = slices.Clone(b)
+	for i := 0; i+size <= len(b); i += size {
+		slices.Reverse(b[i : i+size])
+	}
+	return b
+}
+
+// extent returns the number of bytes in the beginning
+// of a file that this matchlet and its children need.
+func (m *mimeMatchlet) extent() int {
+	n := m.offset + m.rangeLen - 1 + len(m.value)
+	for _, c := range m.children {
+		n = max(n, c.extent())
+	}
+	return n
+}
+
+// match checks whether this matchlet matches the data, at any offset within its range,
+// and if it has children, whether at least one of them matches the data too.
+func (m *mimeMatchlet) match(data []byte) bool {
+	found := false
+	for off := m.offset; off < m.offset+m.rangeLen && off+len(m.value) <= len(data); off++ {
+		if m.matchAt(data[off : off+len(m.value)]) {
+			found = true
+			break
+		}
+	}
+
+	if !found || len(m.children) == 0 {
+		return found
+	}
+	return slices.ContainsFunc(m.children, func(c *mimeMatchlet) bool {
+		return c.match(data)
+	})
+}
+
+func (m *mimeMatchlet) matchAt(data []byte) bool {
+	if m.mask == nil {
+		return bytes.Equal(data, m.value)
+	}
+
+	for i := range data {
+		if data[i]&m.mask[i] != m.value[i]&m.mask[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// TypeByName returns the MIME type of a file based on its name (e.g. "README.md"),
+// or an empty string if none of the database's globs match it. If several globs
+// match, the one with the highest weight, and then the longest pattern, wins.
+// Case-insensitive globs match the name in any case.
+func (db *MIMEDatabase) TypeByName(name string) string {
+	types := db.typesByName(name)
+	if len(types) == 0 {
+		return ""
+	}
+	return types[0]
+}
+
+// typesByName returns all the MIME types whose globs match
+// the file name, with the best weight and pattern length.
+func (db *MIMEDatabase) typesByName(name string) []string {
+	name = filepath.Base(name)
+	lower := strings.ToLower(name)
+
+	var best *mimeGlob
+	var types []string
+	for i, g := range db.globs {
+		n := lower
+		if g.caseSensitive {
+			n = name
+		}
+		if ok, _ := path.Match(g.pattern, n); !ok {
+			continue
+		}
+
+		switch {
+		case best == nil || g.weight > best.weight || (g.weight == best.weight && len(g.pattern) > len(best.pattern)):
+			best, types = &db.globs[i], []string{g.mimeType}
+		case g.weight == best.weight && len(g.pattern) == len(best.pattern) && !slices.Contains(types, g.mimeType):
+			types = append(types, g.mimeType)
+		}
+	}
+
+	return types
+}
+
+// TypeByContent returns the MIME type of a file based on its content, or an empty
+// string if none of the database's magic rules match it. If several rules match,
+// the one with the highest priority wins. The data should be at least the first
+// [MIMEDatabase.MagicSize] bytes of the file, or the whole file if it's shorter.
+func (db *MIMEDatabase) TypeByContent(data []byte) string {
+	for _, rule := range db.magic {
+		if slices.ContainsFunc(rule.matchlets, func(m *mimeMatchlet) bool {
+			return m.match(data)
+		}) {
+			return rule.mimeType
+		}
+	}
+	return ""
+}
+
+// MagicSize returns the number of bytes in the beginning of a
+// file that the database's magic rules need to check its content.
+func (db *MIMEDatabase) MagicSize() int {
+	return db.magicSize
+}
+
+// TypeOfFile returns the MIME type of a file, based on its name and, if that's
+// ambiguous, its content, like the recommended checking order of the specification.
+// If neither is recognized, the type is "text/plain" if the beginning of the file
+// looks like UTF-8 text, or "application/octet-stream" otherwise. Directories
+// are "inode/directory".
+func (db *MIMEDatabase) TypeOfFile(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	if info.IsDir() {
+		return mimeTypeDirectory, nil
+	}
+
+	types := db.typesByName(path)
+	if len(types) == 1 {
+		return types[0], nil
+	}
+
+	f, err := os.Open(path) //gosec:disable G304
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	data := make([]byte, max(db.magicSize, mimeTextSniffLen))
+	n, err := io.ReadFull(f, data)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return "", err
+	}
+	data = data[:n]
+
+	// Prefer a glob match which is equal to or more specific than the magic match.
+	if t := db.TypeByContent(data); t != "" {
+		if len(types) == 0 {
+			return t, nil
+		}
+		if i := slices.IndexFunc(types, func(g string) bool { return db.IsSubclass(g, t) }); i >= 0 {
+			return types[i], nil
+		}
+	}
+
+	switch {
+	case len(types) > 0:
+		return types[0], nil
+	case looksLikeText(data[:min(n, mimeTextSniffLen)]):
+		return mimeTypeTextPlain, nil
+	default:
+		return mimeTypeOctetStream, nil
+	}
+}
+
+// looksLikeText checks whether data is UTF-8 text without control characters,
+// except whitespace. A multi-byte character may be truncated at the end.
+func looksLikeText(data []byte) bool {
+	for len(data) > 0 {
+		r, size := utf8.DecodeRune(data)
+		switch {
+		case r == utf8.RuneError && size <= 1:
+			return !utf8.FullRune(data)
+		case r < ' ' && !strings.ContainsRune("\t\n\r\f", r), r == 0x7f:
+			return false
+		}
+		data = data[size:]
+	}
+	return true
+}
+
+// Unalias returns the canonical name of a MIME type,
+// if it's an alias (e.g. "text/xml" for "application/xml").
+func (db *MIMEDatabase) Unalias(mimeType string) string {
+	if t, ok := db.aliases[mimeType]; ok {
+		return t
+	}
+	return mimeType
+}
+
+// Parents returns the direct parent types of a MIME type (e.g. "text/plain"
+// for "application/x-shellscript"), after resolving aliases. In addition to
+// the database's subclasses, all "text/*" types are subclasses of "text/plain",
+// and all types except "inode/*" are subclasses of "application/octet-stream".
+func (db *MIMEDatabase) Parents(mimeType string) []string {
+	mimeType = db.Unalias(mimeType)
+	parents := slices.Clone(db.parents[mimeType])
+
+	if strings.HasPrefix(mimeType, "text/") && mimeType != mimeTypeTextPlain &&
+		!slices.Contains(parents, mimeTypeTextPlain) {
+		parents = append(parents, mimeTypeTextPlain)
+	}
+	if !strings.HasPrefix(mimeType, "inode/") && mimeType != mimeTypeOctetStream &&
+		!slices.Contains(parents, mimeTypeOctetStream) {
+		parents = append(parents, mimeTypeOctetStream)
+	}
+
+	return parents
+}
+
+// IsSubclass checks whether a MIME type is equal to, or a direct
+// or indirect subclass of, another type, after resolving aliases.
+func (db *MIMEDatabase) IsSubclass(mimeType, parent string) bool {
+	mimeType, parent = db.Unalias(mimeType), db.Unalias(parent)
+	visited := map[string]bool{}
+	queue := []string{mimeType}
+	for len(queue) > 0 {
+		t := queue[0]
+		queue = queue[1:]
+		if t == parent {
+			return true
+		}
+		if visited[t] {
+			continue
+		}
+		visited[t] = true
+		queue = append(queue, db.Parents(t)...)
+	}
+	return false
+}
@@ -0,0 +1,266 @@
+package xdg
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// magicMatchlet returns a matchlet line for a "magic" file.
+func magicMatchlet(prefix, value, suffix string) string {
+	n := binary.BigEndian.AppendUint16(nil, uint16(len(value)))
+	return prefix + "=" + string(n) + value + suffix + "\n"
+}
+
+func writeMIMEDatabase(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+
+	for name, content := range files {
+		path := filepath.Join(dir, "mime", name)
+		if err := os.MkdirAll(filepath.Dir(path), NewDirectoryPermissions); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), NewFilePermissions); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func testMIMEDatabase(t *testing.T) *MIMEDatabase {
+	t.Helper()
+
+	dir := t.TempDir()
+	home := filepath.Join(dir, "home")
+	sys := filepath.Join(dir, "sys")
+
+	writeMIMEDatabase(t, sys, map[string]string{
+		"globs2": "# Comment\n50:text/plain:*.txt\n50:text/x-c:*.c:cs\n50:text/x-c++:*.C:cs\n" +
+			"50:text/x-readme:readme*\n10:text/x-readme:*.md\n50:text/markdown:*.md\n" +
+			"50:image/png:*.png\n50:application/x-old:*.old\n50:application/zip:*.zip\n" +
+			"50:application/x-foo:*.foo\n50:application/x-bar:*.foo\n",
+		"magic": mimeMagicHeader +
+			"[50:image/png]\n" + magicMatchlet(">0", "\x89PNG", "") +
+			"[60:application/zip]\n" + magicMatchlet(">0", "PK\x03\x04", "") +
+			"[40:application/x-bar]\n" + magicMatchlet(">0", "BAR", "") +
+			"[50:application/x-ranged]\n" + magicMatchlet(">4", "needle", "+10") +
+			"[50:application/x-masked]\n" + magicMatchlet(">0", "\xf0\x00", "&\xf0\xff") +
+			"[50:application/x-word]\n" + magicMatchlet(">0", "\x12\x34", "~2") +
+			"[50:application/x-deleted]\n" + magicMatchlet(">0", "OLD", "") +
+			"[50:application/x-nested]\n" + magicMatchlet(">0", "NEST", "") +
+			magicMatchlet("1>4", "A", "") + magicMatchlet("1>4", "B", ""),
+		"aliases":    "text/x-markdown text/markdown\n",
+		"subclasses": "application/x-bar application/zip\ntext/markdown text/plain\n",
+	})
+	writeMIMEDatabase(t, home, map[string]string{
+		"globs2": "50:application/x-old:__NOGLOBS__\n50:application/x-new:*.old\n",
+		"magic": mimeMagicHeader +
+			"[50:image/x-home]\n" + magicMatchlet(">0", "\x89PNG", "") +
+			"[50:application/x-deleted]\n" + magicMatchlet(">0", "__NOMAGIC__", "") + magicMatchlet(">0", "NEW", ""),
+	})
+
+	r := &Resolver{Getenv: mapEnv(map[string]string{
+		"XDG_DATA_HOME": home,
+		"XDG_DATA_DIRS": sys,
+	})}
+
+	db, err := r.LoadMIMEDatabase()
+	if err != nil {
+		t.Fatalf("LoadMIMEDatabase() error = %v", err)
+	}
+	return db
+}
+
+func TestMIMEDatabaseTypeByName(t *testing.T) {
+	db := testMIMEDatabase(t)
+
+	tests := []struct {
+		name string
+		file string
+		want string
+	}{
+		{name: "suffix", file: "notes.txt", want: "text/plain"},
+		{name: "case_insensitive", file: "NOTES.TXT", want: "text/plain"},
+		{name: "case_sensitive", file: "main.c", want: "text/x-c"},
+		{name: "case_sensitive_upper", file: "main.C", want: "text/x-c++"},
+		{name: "full_path", file: filepath.Join("dir.txt", "image.png"), want: "image/png"},
+		{name: "weight", file: "readme.md", want: "text/x-readme"},
+		{name: "longest_pattern", file: "notes.md", want: "text/markdown"},
+		{name: "noglobs_override", file: "file.old", want: "application/x-new"},
+		{name: "unknown", file: "file.unknown"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := db.TypeByName(tt.file); got != tt.want {
+				t.Errorf("TypeByName(%q) = %q, want %q", tt.file, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMIMEDatabaseTypeByContent(t *testing.T) {
+	db := testMIMEDatabase(t)
+
+	// Words are matched in the host's byte order.
+	word := "\x34\x12"
+	if !hostLittleEndian {
+		word = "\x12\x34"
+	}
+
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{name: "more_important_dir", data: "\x89PNG\r\n", want: "image/x-home"},
+		{name: "priority", data: "PK\x03\x04", want: "application/zip"},
+		{name: "nomagic_override", data: "OLD"},
+		{name: "nomagic_replacement", data: "NEW", want: "application/x-deleted"},
+		{name: "nomagic_literal", data: "__NOMAGIC__"},
+		{name: "range", data: "0123456789needle", want: "application/x-ranged"},
+		{name: "out_of_range", data: "01234567890123needle"},
+		{name: "mask", data: "\xf5\x00", want: "application/x-masked"},
+		{name: "mask_mismatch", data: "\xf5\x01"},
+		{name: "word_size", data: word, want: "application/x-word"},
+		{name: "nested", data: "NESTB", want: "application/x-nested"},
+		{name: "nested_mismatch", data: "NESTC"},
+		{name: "short", data: "PK"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := db.TypeByContent([]byte(tt.data)); got != tt.want {
+				t.Errorf("TypeByContent(%q) = %q, want %q", tt.data, got, tt.want)
+			}
+		})
+	}
+
+	if got, want := db.MagicSize(), 19; got != want {
+		t.Errorf("MagicSize() = %d, want %d", got, want)
+	}
+}
+
+func TestMIMEDatabaseTypeOfFile(t *testing.T) {
+	db := testMIMEDatabase(t)
+	dir := t.TempDir()
+
+	tests := []struct {
+		name string
+		file string
+		data string
+		want string
+	}{
+		{name: "glob", file: "image.png", data: "not really", want: "image/png"},
+		{name: "magic", file: "archive", data: "PK\x03\x04...", want: "application/zip"},
+		{name: "ambiguous_glob_magic", file: "file.foo", data: "BAR", want: "application/x-bar"},
+		{name: "ambiguous_glob_subclass", file: "other.foo", data: "PK\x03\x04", want: "application/x-bar"},
+		{name: "ambiguous_glob_no_magic", file: "third.foo", data: "xyz", want: "application/x-foo"},
+		{name: "text", file: "unknown", data: "héllo\n", want: "text/plain"},
+		{name: "binary", file: "unknown.bin", data: "\x00\x01\x02", want: "application/octet-stream"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, tt.file)
+			if err := os.WriteFile(path, []byte(tt.data), NewFilePermissions); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := db.TypeOfFile(path)
+			if err != nil {
+				t.Fatalf("TypeOfFile() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("TypeOfFile() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if got, _ := db.TypeOfFile(dir); got != "inode/directory" {
+		t.Errorf("TypeOfFile(dir) = %q, want %q", got, "inode/directory")
+	}
+	if _, err := db.TypeOfFile(filepath.Join(dir, "missing")); err == nil {
+		t.Error("TypeOfFile(missing) error = nil, want error")
+	}
+}
+
+func TestMIMEDatabaseParents(t *testing.T) {
+	db := testMIMEDatabase(t)
+
+	if got := db.Unalias("text/x-markdown"); got != "text/markdown" {
+		t.Errorf("Unalias() = %q, want %q", got, "text/markdown")
+	}
+
+	tests := []struct {
+		mimeType string
+		want     []string
+	}{
+		{mimeType: "application/x-bar", want: []string{"application/zip", "application/octet-stream"}},
+		{mimeType: "text/x-markdown", want: []string{"text/plain", "application/octet-stream"}},
+		{mimeType: "text/x-c", want: []string{"text/plain", "application/octet-stream"}},
+		{mimeType: "text/plain", want: []string{"application/octet-stream"}},
+		{mimeType: "inode/directory"},
+		{mimeType: "application/octet-stream"},
+	}
+
+	for _, tt := range tests {
+		if got := db.Parents(tt.mimeType); !slices.Equal(got, tt.want) {
+			t.Errorf("Parents(%q) = %q, want %q", tt.mimeType, got, tt.want)
+		}
+	}
+
+	if !db.IsSubclass("text/x-markdown", "application/octet-stream") {
+		t.Error("IsSubclass(text/x-markdown, application/octet-stream) = false, want true")
+	}
+	if !db.IsSubclass("text/markdown", "text/x-markdown") {
+		t.Error("IsSubclass(text/markdown, text/x-markdown) = false, want true")
+	}
+	if db.IsSubclass("application/zip", "application/x-bar") {
+		t.Error("IsSubclass(application/zip, application/x-bar) = true, want false")
+	}
+}
+
+func TestParseMIMEMagic(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{name: "header", data: "MIME-Magic\n"},
+		{name: "section", data: mimeMagicHeader + "[50:text/plain\n"},
+		{name: "priority", data: mimeMagicHeader + "[x:text/plain]\n"},
+		{name: "orphan", data: mimeMagicHeader + magicMatchlet(">0", "a", "")},
+		{name: "indent", data: mimeMagicHeader + "[50:a/b]\n" + magicMatchlet("1>0", "a", "")},
+		{name: "truncated", data: mimeMagicHeader + "[50:a/b]\n>0=\x00\x05ab"},
+		{name: "unterminated", data: mimeMagicHeader + "[50:a/b]\n>0=\x00\x01a"},
+		{name: "flag", data: mimeMagicHeader + "[50:a/b]\n" + magicMatchlet(">0", "a", "!")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parseMIMEMagic([]byte(tt.data)); err == nil {
+				t.Errorf("parseMIMEMagic() error = nil, want error")
+			}
+		})
+	}
+}
+
+func TestLooksLikeText(t *testing.T) {
+	tests := []struct {
+		data string
+		want bool
+	}{
+		{data: "", want: true},
+		{data: "hello\tworld\r\n", want: true},
+		{data: "truncated \xc3", want: true},
+		{data: "invalid \xc3 utf-8"},
+		{data: "nul\x00"},
+		{data: "del\x7f"},
+	}
+
+	for _, tt := range tests {
+		if got := looksLikeText([]byte(tt.data)); got != tt.want {
+			t.Errorf("looksLikeText(%q) = %v, want %v", tt.data, got, tt.want)
+		}
+	}
+}
@@ -36,6 +36,18 @@ func MustStateHome() string {
 	return must(StateHome())
 }
 
+// MustHomeDir is like [HomeDir]. It discards the error
+// and returns only the path, but panics if there is an error.
+func MustHomeDir() string {
+	return must(HomeDir())
+}
+
+// MustRuntimeDir is like [RuntimeDir]. It discards the error
+// and returns only the path, but panics if there is an error.
+func MustRuntimeDir() string {
+	return must(RuntimeDir())
+}
+
 func must[T any](path T, err error) T {
 	if err != nil {
 		panic(err)
@@ -0,0 +1,282 @@
+package xdg
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Platform selects the default paths and path semantics that a [Resolver] uses.
+// Non-host platforms allow computing the paths of other operating systems on any
+// host (e.g. Windows paths in Linux), which is useful for cross-platform tests.
+//
+// Non-host platforms should be used with an explicit [Resolver.Home].
+// They don't access the local filesystem: they don't filter out non-existing
+// directories in [Resolver.ConfigDirs] and [Resolver.DataDirs], they ignore
+// the "user-dirs.dirs" file in [Resolver.UserDir], and they return an error
+// in functions that access files, such as [Resolver.FindConfigFile].
+type Platform string
+
+// Supported platforms.
+const (
+	// PlatformHost represents the current operating system. It's the default.
+	PlatformHost Platform = ""
+	// PlatformUnix represents all Unix flavors, except macOS.
+	PlatformUnix Platform = "unix"
+	// PlatformDarwin represents macOS.
+	PlatformDarwin Platform = "darwin"
+	// PlatformWindows represents Microsoft Windows. Its default paths are
+	// based on [Resolver.KnownFolders], and it uses Windows path semantics.
+	PlatformWindows Platform = "windows"
+)
+
+// pathSyntax is a set of path semantics, similar to [filepath],
+// but not necessarily for the current operating system.
+type pathSyntax struct {
+	join     func(elem ...string) string
+	clean    func(path string) string
+	isAbs    func(path string) bool
+	listSep  string
+	winVars  bool // "%var%" instead of "~".
+	readOnly bool // Don't access the local filesystem.
+}
+
+var (
+	hostSyntax = pathSyntax{
+		join:    filepath.Join,
+		clean:   filepath.Clean,
+		isAbs:   filepath.IsAbs,
+		listSep: listSeparator,
+		winVars: os.PathSeparator == '\\',
+	}
+
+	posixSyntax = pathSyntax{
+		join:     path.Join,
+		clean:    path.Clean,
+		isAbs:    path.IsAbs,
+		listSep:  ":",
+		readOnly: true,
+	}
+
+	windowsSyntax = pathSyntax{
+		join:     windowsJoin,
+		clean:    windowsClean,
+		isAbs:    windowsIsAbs,
+		listSep:  ";",
+		winVars:  true,
+		readOnly: true,
+	}
+)
+
+// unixDefaults returns the default paths of all Unix flavors, except macOS.
+func unixDefaults(join func(elem ...string) string) Defaults {
+	return Defaults{
+		CacheHome:  func(home string) string { return join(home, ".cache") },
+		ConfigHome: func(home string) string { return join(home, ".config") },
+		ConfigDirs: func(_ string) string { return "/etc/xdg" },
+		DataHome:   func(home string) string { return join(home, ".local/share") },
+		DataDirs:   func(_ string) string { return "/usr/local/share:/usr/share" },
+		StateHome:  func(home string) string { return join(home, ".local/state") },
+		UserDir: func(home, name string) string {
+			if name == UserDirDesktop {
+				return join(home, "Desktop")
+			}
+			return home
+		},
+	}
+}
+
+var darwinUserDirs = map[string]string{
+	UserDirDesktop:     "Desktop",
+	UserDirDocuments:   "Documents",
+	UserDirDownload:    "Downloads",
+	UserDirMusic:       "Music",
+	UserDirPictures:    "Pictures",
+	UserDirPublicShare: "Public",
+	UserDirVideos:      "Movies",
+}
+
+// darwinDefaults returns the default paths of macOS.
+func darwinDefaults(join func(elem ...string) string) Defaults {
+	return Defaults{
+		CacheHome:  func(home string) string { return join(home, "Library/Caches") },
+		ConfigHome: func(home string) string { return join(home, ".config") },
+		ConfigDirs: func(home string) string {
+			return strings.Join([]string{
+				join(home, "Library/Application Support"),
+				"/Library/Application Support",
+				"/etc/xdg",
+			}, ":")
+		},
+		DataHome: func(home string) string { return join(home, "Library/Application Support") },
+		DataDirs: func(home string) string {
+			return strings.Join([]string{
+				"/Library/Application Support",
+				join(home, ".local/share"),
+				"/usr/local/share:/usr/share",
+			}, ":")
+		},
+		StateHome: func(home string) string { return join(home, "Library/Application Support") },
+		UserDir: func(home, name string) string {
+			if subdir, ok := darwinUserDirs[name]; ok {
+				return join(home, subdir)
+			}
+			return home
+		},
+	}
+}
+
+// windowsUserDirs maps well-known user directory names to Windows Known Folder
+// names (without the "FOLDERID_" prefix), and fallback subdirectories of the
+// user's home directory. An empty subdirectory means the home directory itself.
+var windowsUserDirs = map[string]struct {
+	folder string
+	subdir string
+}{
+	UserDirDesktop:     {"Desktop", "Desktop"},
+	UserDirDocuments:   {"Documents", "Documents"},
+	UserDirDownload:    {"Downloads", "Downloads"},
+	UserDirMusic:       {"Music", "Music"},
+	UserDirPictures:    {"Pictures", "Pictures"},
+	UserDirPublicShare: {"Public", ""},
+	UserDirTemplates:   {"Templates", ""},
+	UserDirVideos:      {"Videos", "Videos"},
+}
+
+// windowsDefaults returns the default paths of Windows. The knownFolder function
+// receives a Known Folder name without the "FOLDERID_" prefix (e.g. "LocalAppData"),
+// and returns its path, or an empty string if it's unknown, in which case
+// this function constructs a default speculative path.
+func windowsDefaults(join func(elem ...string) string, knownFolder func(name string) string) Defaults {
+	folder := func(name, home string, fallback ...string) string {
+		if path := knownFolder(name); path != "" {
+			return path
+		}
+		return join(append([]string{home}, fallback...)...)
+	}
+
+	localAppData := func(home string) string { return folder("LocalAppData", home, "AppData", "Local") }
+	programData := func(_ string) string { return folder("ProgramData", `C:\`, "ProgramData") }
+
+	return Defaults{
+		CacheHome:  func(home string) string { return join(localAppData(home), "Cache") },
+		ConfigHome: func(home string) string { return folder("RoamingAppData", home, "AppData", "Roaming") },
+		ConfigDirs: programData,
+		DataHome:   localAppData,
+		DataDirs:   programData,
+		StateHome:  localAppData,
+		UserDir: func(home, name string) string {
+			if d, ok := windowsUserDirs[name]; ok {
+				return folder(d.folder, home, d.subdir)
+			}
+			return home
+		},
+	}
+}
+
+// syntax returns the path semantics of the resolver's platform.
+func (r *Resolver) syntax() pathSyntax {
+	switch {
+	case r.Platform == PlatformHost || r.Platform == hostPlatform:
+		return hostSyntax
+	case r.Platform == PlatformWindows:
+		return windowsSyntax
+	default:
+		return posixSyntax
+	}
+}
+
+// checkHost returns an error if the resolver's
+// platform doesn't access the local filesystem.
+func (r *Resolver) checkHost() error {
+	if r.syntax().readOnly {
+		return fmt.Errorf("unsupported with non-host platform %q", r.Platform)
+	}
+	return nil
+}
+
+// platformDefaults returns the default paths of the resolver's platform.
+func (r *Resolver) platformDefaults() Defaults {
+	join := r.syntax().join
+	switch r.Platform {
+	case PlatformUnix:
+		return unixDefaults(join)
+	case PlatformDarwin:
+		return darwinDefaults(join)
+	case PlatformWindows:
+		return windowsDefaults(join, func(name string) string {
+			if path := r.KnownFolders[name]; path != "" {
+				return path
+			}
+			return hostKnownFolder(name)
+		})
+	default:
+		return hostDefaults()
+	}
+}
+
+// windowsVolume returns the leading drive letter ("C:") or
+// UNC prefix ("\\server\share") of a Windows path, if any.
+func windowsVolume(path string) string {
+	if len(path) >= 2 && path[1] == ':' && ('a' <= path[0]|0x20 && path[0]|0x20 <= 'z') {
+		return path[:2]
+	}
+
+	if strings.HasPrefix(path, `\\`) {
+		parts := strings.SplitN(path[2:], `\`, 3)
+		if len(parts) >= 2 && parts[0] != "" && parts[1] != "" {
+			return `\\` + parts[0] + `\` + parts[1]
+		}
+	}
+
+	return ""
+}
+
+// windowsClean is like [filepath.Clean] in Windows.
+func windowsClean(p string) string {
+	p = strings.ReplaceAll(p, "/", `\`)
+	vol := windowsVolume(p)
+	rest := p[len(vol):]
+	if rest == "" {
+		if vol == "" {
+			return "."
+		}
+		if strings.HasPrefix(vol, `\\`) {
+			return vol + `\`
+		}
+		return vol + "."
+	}
+
+	rest = path.Clean(strings.ReplaceAll(rest, `\`, "/"))
+	return vol + strings.ReplaceAll(rest, "/", `\`)
+}
+
+// windowsJoin is like [filepath.Join] in Windows.
+func windowsJoin(elem ...string) string {
+	var nonEmpty []string
+	for _, e := range elem {
+		if e != "" {
+			nonEmpty = append(nonEmpty, e)
+		}
+	}
+	if len(nonEmpty) == 0 {
+		return ""
+	}
+	return windowsClean(strings.Join(nonEmpty, `\`))
+}
+
+// windowsIsAbs is like [filepath.IsAbs] in Windows.
+func windowsIsAbs(path string) bool {
+	path = strings.ReplaceAll(path, "/", `\`)
+	vol := windowsVolume(path)
+	if strings.HasPrefix(vol, `\\`) {
+		return true
+	}
+	return vol != "" && strings.HasPrefix(path[len(vol):], `\`)
+}
+
+// windowsVarPattern matches "%var%" references in Windows paths.
+var windowsVarPattern = regexp.MustCompile(`%(\w+?)%`)
@@ -0,0 +1,175 @@
+package xdg
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestPlatformDarwin(t *testing.T) {
+	t.Parallel()
+
+	r := &Resolver{
+		Getenv:   mapEnv(map[string]string{}),
+		Home:     "/Users/me",
+		Platform: PlatformDarwin,
+	}
+
+	tests := []struct {
+		name string
+		fn   func() (string, error)
+		want string
+	}{
+		{name: "CacheHome", fn: r.CacheHome, want: "/Users/me/Library/Caches"},
+		{name: "ConfigHome", fn: r.ConfigHome, want: "/Users/me/.config"},
+		{name: "DataHome", fn: r.DataHome, want: "/Users/me/Library/Application Support"},
+		{name: "StateHome", fn: r.StateHome, want: "/Users/me/Library/Application Support"},
+		{
+			name: "DownloadDir",
+			fn:   func() (string, error) { return r.UserDir(UserDirDownload) },
+			want: "/Users/me/Downloads",
+		},
+		{
+			name: "VideosDir",
+			fn:   func() (string, error) { return r.UserDir(UserDirVideos) },
+			want: "/Users/me/Movies",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.fn()
+			if err != nil {
+				t.Errorf("%s() error = %v", tt.name, err)
+			}
+			if got != tt.want {
+				t.Errorf("%s() = %q, want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+
+	if hostPlatform == PlatformDarwin {
+		return // The rest depends on the existence of directories.
+	}
+
+	got, err := r.DataDirs()
+	if err != nil {
+		t.Errorf("DataDirs() error = %v", err)
+	}
+	want := []string{"/Library/Application Support", "/Users/me/.local/share", "/usr/local/share", "/usr/share"}
+	if !slices.Equal(got, want) {
+		t.Errorf("DataDirs() = %q, want %q", got, want)
+	}
+}
+
+func TestPlatformWindows(t *testing.T) {
+	t.Parallel()
+
+	r := &Resolver{
+		Getenv: mapEnv(map[string]string{
+			"XDG_STATE_HOME": `%USERPROFILE%/State/`,
+			"XDG_DATA_DIRS":  `D:\Data;relative\path;E:\More`,
+		}),
+		Home:     `C:\Users\me`,
+		Platform: PlatformWindows,
+		KnownFolders: map[string]string{
+			"LocalAppData": `D:\Local`,
+			"ProgramData":  `C:\ProgramData`,
+			"Downloads":    `E:\Downloads`,
+		},
+	}
+	if hostPlatform == PlatformWindows {
+		r.KnownFolders["RoamingAppData"] = `C:\Users\me\AppData\Roaming`
+		r.KnownFolders["Pictures"] = `C:\Users\me\Pictures`
+	}
+
+	tests := []struct {
+		name string
+		fn   func() (string, error)
+		want string
+	}{
+		{name: "CacheHome", fn: r.CacheHome, want: `D:\Local\Cache`},
+		{name: "ConfigHome", fn: r.ConfigHome, want: `C:\Users\me\AppData\Roaming`},
+		{name: "DataHome", fn: r.DataHome, want: `D:\Local`},
+		{name: "StateHome", fn: r.StateHome, want: `C:\Users\me\State`},
+		{
+			name: "DownloadDir",
+			fn:   func() (string, error) { return r.UserDir(UserDirDownload) },
+			want: `E:\Downloads`,
+		},
+		{
+			name: "PicturesDir",
+			fn:   func() (string, error) { return r.UserDir(UserDirPictures) },
+			want: `C:\Users\me\Pictures`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.fn()
+			if err != nil {
+				t.Errorf("%s() error = %v", tt.name, err)
+			}
+			if got != tt.want {
+				t.Errorf("%s() = %q, want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+
+	if hostPlatform == PlatformWindows {
+		return // The rest depends on the existence of directories.
+	}
+
+	got, err := r.DataDirs()
+	if err != nil {
+		t.Errorf("DataDirs() error = %v", err)
+	}
+	if want := []string{`D:\Data`, `E:\More`}; !slices.Equal(got, want) {
+		t.Errorf("DataDirs() = %q, want %q", got, want)
+	}
+
+	got, err = r.ConfigDirs()
+	if err != nil {
+		t.Errorf("ConfigDirs() error = %v", err)
+	}
+	if want := []string{`C:\ProgramData`}; !slices.Equal(got, want) {
+		t.Errorf("ConfigDirs() = %q, want %q", got, want)
+	}
+
+	if _, err := r.FindConfigFile("my_app", "my_file"); err == nil {
+		t.Error("FindConfigFile() error = nil, want error")
+	}
+}
+
+func TestWindowsPaths(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		path      string
+		wantClean string
+		wantAbs   bool
+	}{
+		{path: "", wantClean: "."},
+		{path: `C:`, wantClean: `C:.`},
+		{path: `C:\`, wantClean: `C:\`, wantAbs: true},
+		{path: `c:/foo//bar/../baz/`, wantClean: `c:\foo\baz`, wantAbs: true},
+		{path: `C:foo\..\bar`, wantClean: `C:bar`},
+		{path: `\foo`, wantClean: `\foo`},
+		{path: `foo\.\bar`, wantClean: `foo\bar`},
+		{path: `\\server\share\dir\..\file`, wantClean: `\\server\share\file`, wantAbs: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := windowsClean(tt.path); got != tt.wantClean {
+				t.Errorf("windowsClean(%q) = %q, want %q", tt.path, got, tt.wantClean)
+			}
+			if got := windowsIsAbs(tt.path); got != tt.wantAbs {
+				t.Errorf("windowsIsAbs(%q) = %v, want %v", tt.path, got, tt.wantAbs)
+			}
+		})
+	}
+
+	if got, want := windowsJoin(`C:\`, "", "a", `b\`), `C:\a\b`; got != want {
+		t.Errorf("windowsJoin() = %q, want %q", got, want)
+	}
+}
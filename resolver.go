@@ -0,0 +1,249 @@
+package xdg
+
+import (
+	"iter"
+	"os"
+)
+
+// Resolver resolves XDG paths in a specific environment. The package-level
+// functions (e.g. [ConfigHome], [FindConfigFile]) use a default resolver,
+// which is based on the current process's environment variables and home
+// directory, and on the current operating system's default paths and semantics.
+//
+// Custom resolvers are useful for resolving paths for a different environment
+// (e.g. a child process), and for running isolated tests in parallel.
+// The zero value is equivalent to the default resolver. A resolver
+// is safe for concurrent use, as long as it's not modified.
+//
+// Resolver also has Create* methods, which select the base directory with a
+// method expression, e.g. r.CreateDir((*Resolver).ConfigHome, "my_app").
+type Resolver struct {
+	// Getenv retrieves the value of an environment variable,
+	// or returns an empty string if it's not set. If this
+	// field is nil, the resolver uses [os.Getenv].
+	Getenv func(key string) string
+
+	// Home is the absolute path of the user's home directory.
+	// If this field is empty, the resolver uses [HomeDir].
+	//
+	// Note that in Windows most default paths are Known Folders
+	// of the current user, so they're not based on this field.
+	Home string
+
+	// Defaults override the platform's default paths,
+	// which are used when XDG environment variables are not set.
+	Defaults Defaults
+
+	// Platform selects the default paths and path semantics.
+	// If this field is empty, the resolver uses [PlatformHost].
+	Platform Platform
+
+	// KnownFolders are the paths of Windows Known Folders, by name without
+	// the "FOLDERID_" prefix (e.g. "LocalAppData", "RoamingAppData",
+	// "ProgramData", "Downloads"). They're used only with [PlatformWindows].
+	// Missing folders are queried from the host if it's Windows,
+	// or derived from the home directory otherwise.
+	KnownFolders map[string]string
+}
+
+// Defaults is a set of functions that return default paths when XDG environment
+// variables are not set. Each function receives the resolver's home directory.
+// The values of [Defaults.ConfigDirs] and [Defaults.DataDirs] are lists
+// separated by the platform's list separator. Nil functions are ignored,
+// i.e. the platform's default paths are used instead.
+type Defaults struct {
+	CacheHome  func(home string) string
+	ConfigHome func(home string) string
+	ConfigDirs func(home string) string
+	DataHome   func(home string) string
+	DataDirs   func(home string) string
+	StateHome  func(home string) string
+
+	// UserDir also receives the name of a well-known user directory,
+	// such as [UserDirDownload].
+	UserDir func(home, name string) string
+}
+
+var defaultResolver = &Resolver{}
+
+// CacheHome is like the package-level [CacheHome], but uses r's environment.
+func (r *Resolver) CacheHome() (string, error) {
+	return r.dir("XDG_CACHE_HOME", r.Defaults.CacheHome, r.platformDefaults().CacheHome)
+}
+
+// ConfigHome is like the package-level [ConfigHome], but uses r's environment.
+func (r *Resolver) ConfigHome() (string, error) {
+	return r.dir("XDG_CONFIG_HOME", r.Defaults.ConfigHome, r.platformDefaults().ConfigHome)
+}
+
+// ConfigDirs is like the package-level [ConfigDirs], but uses r's environment.
+func (r *Resolver) ConfigDirs() ([]string, error) {
+	return r.dirs("XDG_CONFIG_DIRS", r.Defaults.ConfigDirs, r.platformDefaults().ConfigDirs)
+}
+
+// DataHome is like the package-level [DataHome], but uses r's environment.
+func (r *Resolver) DataHome() (string, error) {
+	return r.dir("XDG_DATA_HOME", r.Defaults.DataHome, r.platformDefaults().DataHome)
+}
+
+// DataDirs is like the package-level [DataDirs], but uses r's environment.
+func (r *Resolver) DataDirs() ([]string, error) {
+	return r.dirs("XDG_DATA_DIRS", r.Defaults.DataDirs, r.platformDefaults().DataDirs)
+}
+
+// StateHome is like the package-level [StateHome], but uses r's environment.
+func (r *Resolver) StateHome() (string, error) {
+	return r.dir("XDG_STATE_HOME", r.Defaults.StateHome, r.platformDefaults().StateHome)
+}
+
+// FindCacheFile is like the package-level [FindCacheFile], but uses r's environment.
+func (r *Resolver) FindCacheFile(appName, filePath string) (string, error) {
+	if err := r.checkHost(); err != nil {
+		return "", err
+	}
+	return findFile(r.CacheHome, nil, appName, filePath)
+}
+
+// FindConfigFile is like the package-level [FindConfigFile], but uses r's environment.
+func (r *Resolver) FindConfigFile(appName, filePath string) (string, error) {
+	if err := r.checkHost(); err != nil {
+		return "", err
+	}
+	return findFile(r.ConfigHome, r.ConfigDirs, appName, filePath)
+}
+
+// FindDataFile is like the package-level [FindDataFile], but uses r's environment.
+func (r *Resolver) FindDataFile(appName, filePath string) (string, error) {
+	if err := r.checkHost(); err != nil {
+		return "", err
+	}
+	return findFile(r.DataHome, r.DataDirs, appName, filePath)
+}
+
+// FindStateFile is like the package-level [FindStateFile], but uses r's environment.
+func (r *Resolver) FindStateFile(appName, filePath string) (string, error) {
+	if err := r.checkHost(); err != nil {
+		return "", err
+	}
+	return findFile(r.StateHome, nil, appName, filePath)
+}
+
+// FindAllConfigFiles is like the package-level [FindAllConfigFiles], but uses r's environment.
+func (r *Resolver) FindAllConfigFiles(appName, filePath string) ([]FoundFile, error) {
+	if err := r.checkHost(); err != nil {
+		return nil, err
+	}
+	return findAllFiles(r.ConfigHome, r.ConfigDirs, appName, filePath)
+}
+
+// FindAllDataFiles is like the package-level [FindAllDataFiles], but uses r's environment.
+func (r *Resolver) FindAllDataFiles(appName, filePath string) ([]FoundFile, error) {
+	if err := r.checkHost(); err != nil {
+		return nil, err
+	}
+	return findAllFiles(r.DataHome, r.DataDirs, appName, filePath)
+}
+
+// ConfigFiles is like the package-level [ConfigFiles], but uses r's environment.
+func (r *Resolver) ConfigFiles(appName, filePath string) iter.Seq2[FoundFile, error] {
+	if err := r.checkHost(); err != nil {
+		return yieldError[FoundFile](err)
+	}
+	return findFiles(r.ConfigHome, r.ConfigDirs, appName, filePath)
+}
+
+// DataFiles is like the package-level [DataFiles], but uses r's environment.
+func (r *Resolver) DataFiles(appName, filePath string) iter.Seq2[FoundFile, error] {
+	if err := r.checkHost(); err != nil {
+		return yieldError[FoundFile](err)
+	}
+	return findFiles(r.DataHome, r.DataDirs, appName, filePath)
+}
+
+// CreateDir is like the package-level [CreateDir], but uses r's environment.
+// The dirType parameter is a resolver method expression, e.g. (*Resolver).ConfigHome.
+func (r *Resolver) CreateDir(
+	dirType func(*Resolver) (string, error),
+	appName string,
+	opts ...CreateOption,
+) (string, error) {
+	if err := r.checkHost(); err != nil {
+		return "", err
+	}
+	return CreateDir(r.bind(dirType), appName, opts...)
+}
+
+// CreateSubdir is like the package-level [CreateSubdir], but uses r's environment.
+// The dirType parameter is a resolver method expression, e.g. (*Resolver).ConfigHome.
+func (r *Resolver) CreateSubdir(
+	dirType func(*Resolver) (string, error),
+	appName, subpath string,
+	opts ...CreateOption,
+) (string, error) {
+	if err := r.checkHost(); err != nil {
+		return "", err
+	}
+	return CreateSubdir(r.bind(dirType), appName, subpath, opts...)
+}
+
+// CreateFile is like the package-level [CreateFile], but uses r's environment.
+// The dirType parameter is a resolver method expression, e.g. (*Resolver).ConfigHome.
+func (r *Resolver) CreateFile(dirType func(*Resolver) (string, error), appName, fileName string) (string, error) {
+	if err := r.checkHost(); err != nil {
+		return "", err
+	}
+	return CreateFile(r.bind(dirType), appName, fileName)
+}
+
+// CreateFilePath is like the package-level [CreateFilePath], but uses r's environment.
+// The dirType parameter is a resolver method expression, e.g. (*Resolver).ConfigHome.
+func (r *Resolver) CreateFilePath(dirType func(*Resolver) (string, error), appName, filePath string) (string, error) {
+	if err := r.checkHost(); err != nil {
+		return "", err
+	}
+	return CreateFilePath(r.bind(dirType), appName, filePath)
+}
+
+// bind returns a base-directory function which calls the
+// given resolver method expression (e.g. (*Resolver).ConfigHome) with r.
+func (r *Resolver) bind(dirType func(*Resolver) (string, error)) func() (string, error) {
+	return func() (string, error) {
+		return dirType(r)
+	}
+}
+
+func (r *Resolver) getenv(key string) string {
+	if r.Getenv != nil {
+		return r.Getenv(key)
+	}
+	return os.Getenv(key)
+}
+
+func (r *Resolver) home() (string, error) {
+	if r.Home != "" {
+		return r.Home, nil
+	}
+	return HomeDir()
+}
+
+// defaultPath calls the custom default function if it's not nil,
+// or the platform's default function otherwise.
+func (r *Resolver) defaultPath(custom, platformDefault func(string) string) (string, error) {
+	home, err := r.home()
+	if err != nil {
+		return "", err
+	}
+
+	if custom != nil {
+		return custom(home), nil
+	}
+	return platformDefault(home), nil
+}
+
+// yieldError returns an iterator that yields only the given error.
+func yieldError[T any](err error) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		yield(zero, err)
+	}
+}
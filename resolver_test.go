@@ -0,0 +1,173 @@
+package xdg
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func mapEnv(env map[string]string) func(string) string {
+	return func(key string) string {
+		return env[key]
+	}
+}
+
+func TestResolver(t *testing.T) {
+	t.Parallel()
+
+	home := filepath.FromSlash("/home/user")
+	r := &Resolver{
+		Getenv: mapEnv(map[string]string{
+			"XDG_CONFIG_HOME": "~/cfg",
+			"XDG_DATA_HOME":   "${HOME}/data",
+			"XDG_STATE_HOME":  "relative/path",
+		}),
+		Home: home,
+		Defaults: Defaults{
+			CacheHome: func(home string) string { return filepath.Join(home, "cache") },
+		},
+	}
+
+	tests := []struct {
+		name    string
+		fn      func() (string, error)
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "custom_default",
+			fn:   r.CacheHome,
+			want: filepath.Join(home, "cache"),
+		},
+		{
+			name: "env_var_with_tilde",
+			fn:   r.ConfigHome,
+			want: filepath.Join(home, "cfg"),
+		},
+		{
+			name: "env_var_with_home",
+			fn:   r.DataHome,
+			want: filepath.Join(home, "data"),
+		},
+		{
+			name:    "relative_env_var",
+			fn:      r.StateHome,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := tt.fn()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("%s() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("%s() = %q, want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolverDirs(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	r := &Resolver{
+		Getenv: mapEnv(map[string]string{}),
+		Home:   dir,
+		Defaults: Defaults{
+			DataDirs: func(home string) string { return home + listSeparator + "/non/existing" },
+		},
+	}
+
+	got, err := r.DataDirs()
+	if err != nil {
+		t.Errorf("DataDirs() error = %v", err)
+	}
+	if want := []string{dir}; !slices.Equal(got, want) {
+		t.Errorf("DataDirs() = %q, want %q", got, want)
+	}
+}
+
+func TestResolverFindConfigFile(t *testing.T) {
+	t.Parallel()
+
+	home, dirs := t.TempDir(), t.TempDir()
+	r := &Resolver{
+		Getenv: mapEnv(map[string]string{
+			"XDG_CONFIG_HOME": home,
+			"XDG_CONFIG_DIRS": dirs,
+		}),
+	}
+
+	dirsResolver := &Resolver{Getenv: mapEnv(map[string]string{"XDG_CONFIG_HOME": dirs})}
+	want, err := dirsResolver.CreateFile((*Resolver).ConfigHome, "my_app", "my_file")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := r.FindConfigFile("my_app", "my_file")
+	if err != nil {
+		t.Errorf("FindConfigFile() error = %v", err)
+	}
+	if got != want {
+		t.Errorf("FindConfigFile() = %q, want %q", got, want)
+	}
+
+	if err := os.Remove(want); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := r.FindConfigFile("my_app", "my_file"); err != nil || got != "" {
+		t.Errorf("FindConfigFile() = %q, %v, want %q", got, err, "")
+	}
+}
+
+func TestResolverCreate(t *testing.T) {
+	t.Parallel()
+
+	cache, state := t.TempDir(), t.TempDir()
+	r := &Resolver{
+		Getenv: mapEnv(map[string]string{
+			"XDG_CACHE_HOME": cache,
+			"XDG_STATE_HOME": state,
+		}),
+	}
+
+	got, err := r.CreateDir((*Resolver).CacheHome, "my_app", WithExclusionMarkers(CacheDirTag))
+	if want := filepath.Join(cache, "my_app"); err != nil || got != want {
+		t.Errorf("CreateDir() = %q, %v, want %q", got, err, want)
+	}
+	if _, err := os.Stat(filepath.Join(got, CacheDirTag.Name)); err != nil {
+		t.Errorf("CreateDir() didn't write %s: %v", CacheDirTag.Name, err)
+	}
+
+	got, err = r.CreateSubdir((*Resolver).StateHome, "my_app", filepath.Join("a", "b"))
+	if want := filepath.Join(state, "my_app", "a", "b"); err != nil || got != want || !absDirExists(got) {
+		t.Errorf("CreateSubdir() = %q, %v, want %q", got, err, want)
+	}
+
+	got, err = r.CreateFile((*Resolver).StateHome, "my_app", "my_file")
+	if want := filepath.Join(state, "my_app", "my_file"); err != nil || got != want {
+		t.Errorf("CreateFile() = %q, %v, want %q", got, err, want)
+	}
+
+	got, err = r.CreateFilePath((*Resolver).CacheHome, "my_app", filepath.Join("sub", "my_file"))
+	if want := filepath.Join(cache, "my_app", "sub", "my_file"); err != nil || got != want {
+		t.Errorf("CreateFilePath() = %q, %v, want %q", got, err, want)
+	}
+	if _, err := os.Stat(got); err != nil {
+		t.Errorf("CreateFilePath() didn't create %q: %v", got, err)
+	}
+
+	other := &Resolver{Platform: PlatformWindows, Home: `C:\Users\me`}
+	if hostPlatform == PlatformWindows {
+		other.Platform = PlatformUnix
+	}
+	if _, err := other.CreateDir((*Resolver).CacheHome, "my_app"); err == nil {
+		t.Error("CreateDir() with non-host platform error = nil, want error")
+	}
+}
@@ -0,0 +1,117 @@
+package xdg
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// RuntimeDir returns the absolute path of the base directory in which
+// user-specific non-essential runtime files and other file objects
+// (such as sockets, named pipes, and lock files) should be stored.
+//
+// The directory must be owned by the current user, and its access mode
+// must be 0700 (this is not checked in Windows). If XDG_RUNTIME_DIR
+// is not set, this function falls back to a replacement directory with
+// the same properties under [os.TempDir], and creates it if necessary.
+// Use [LookupRuntimeDir] to detect whether a fallback was used.
+//
+// Files in this directory may be subjected to periodic clean-up,
+// and the directory itself does not survive a logout or a reboot.
+func RuntimeDir() (string, error) {
+	return defaultResolver.RuntimeDir()
+}
+
+// LookupRuntimeDir is like [RuntimeDir], but it also reports whether the
+// returned path is a fallback replacement directory, rather than the value of
+// the XDG_RUNTIME_DIR environment variable. The specification recommends that
+// applications print a warning message in this case.
+func LookupRuntimeDir() (path string, fallback bool, err error) {
+	return defaultResolver.LookupRuntimeDir()
+}
+
+// RuntimeDir is like the package-level [RuntimeDir], but uses r's environment.
+func (r *Resolver) RuntimeDir() (string, error) {
+	path, _, err := r.LookupRuntimeDir()
+	return path, err
+}
+
+// LookupRuntimeDir is like the package-level [LookupRuntimeDir], but uses r's environment.
+func (r *Resolver) LookupRuntimeDir() (path string, fallback bool, err error) {
+	if err := r.checkHost(); err != nil {
+		return "", false, err
+	}
+
+	path = r.expand(r.getenv("XDG_RUNTIME_DIR"))
+	if path == "" {
+		path, err = fallbackRuntimeDir()
+		return path, true, err
+	}
+
+	if !filepath.IsAbs(path) {
+		return "", false, fmt.Errorf("path in XDG_RUNTIME_DIR is relative: %q", path)
+	}
+
+	if err := checkRuntimeDir(path); err != nil {
+		return "", false, fmt.Errorf("invalid XDG_RUNTIME_DIR: %w", err)
+	}
+
+	return path, false, nil
+}
+
+// fallbackRuntimeDir creates (or reuses) a user-specific replacement for
+// XDG_RUNTIME_DIR. An existing directory is reused only if it's owned by the
+// current user, in which case its access mode is also reset to 0700.
+func fallbackRuntimeDir() (string, error) {
+	path := filepath.Join(os.TempDir(), fallbackRuntimeDirName())
+
+	err := os.Mkdir(path, NewDirectoryPermissions)
+	if err != nil && !errors.Is(err, os.ErrExist) {
+		return "", err
+	}
+
+	// Don't follow symbolic links: the parent directory is shared with other users.
+	info, err := os.Lstat(path)
+	if err != nil {
+		return "", err
+	}
+	if info.Mode()&os.ModeSymlink != 0 {
+		return "", fmt.Errorf("invalid fallback runtime dir: symbolic link: %q", path)
+	}
+	if err := checkRuntimeDirInfo(path, info, false); err != nil {
+		return "", fmt.Errorf("invalid fallback runtime dir: %w", err)
+	}
+
+	// Neutralize the effect of the process's umask, or reset a modified mode.
+	if err := os.Chmod(path, NewDirectoryPermissions); err != nil {
+		return "", err
+	}
+
+	return path, nil
+}
+
+// checkRuntimeDir ensures that the given path is a directory
+// which is owned by the current user, with access mode 0700.
+func checkRuntimeDir(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	return checkRuntimeDirInfo(path, info, true)
+}
+
+func checkRuntimeDirInfo(path string, info os.FileInfo, checkMode bool) error {
+	if !info.IsDir() {
+		return fmt.Errorf("not a directory: %q", path)
+	}
+	if !ownedByCurrentUser(info) {
+		return fmt.Errorf("not owned by the current user: %q", path)
+	}
+	if checkMode && checkPermissions {
+		if perm := info.Mode().Perm(); perm != NewDirectoryPermissions {
+			return fmt.Errorf("access mode of %q is %#o instead of %#o", path, perm, NewDirectoryPermissions)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package xdg
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestLookupRuntimeDir(t *testing.T) {
+	root := t.TempDir()
+
+	valid := filepath.Join(root, "valid")
+	if err := os.Mkdir(valid, NewDirectoryPermissions); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(valid, NewDirectoryPermissions); err != nil {
+		t.Fatal(err)
+	}
+
+	insecure := filepath.Join(root, "insecure")
+	if err := os.Mkdir(insecure, NewDirectoryPermissions); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(insecure, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	file := filepath.Join(root, "file")
+	if err := os.WriteFile(file, nil, NewFilePermissions); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		env     string
+		want    string
+		wantErr bool
+		unix    bool
+	}{
+		{
+			name: "valid_env_var",
+			env:  valid,
+			want: valid,
+		},
+		{
+			name:    "relative_env_var",
+			env:     "relative/path",
+			wantErr: true,
+		},
+		{
+			name:    "non_existing_dir",
+			env:     filepath.Join(root, "missing"),
+			wantErr: true,
+		},
+		{
+			name:    "file_instead_of_dir",
+			env:     file,
+			wantErr: true,
+		},
+		{
+			name:    "insecure_access_mode",
+			env:     insecure,
+			wantErr: true,
+			unix:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.unix && runtime.GOOS == "windows" {
+				t.Skip("access modes are not supported in Windows")
+			}
+			t.Setenv("XDG_RUNTIME_DIR", tt.env)
+
+			got, fallback, err := LookupRuntimeDir()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("LookupRuntimeDir() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("LookupRuntimeDir() = %q, want %q", got, tt.want)
+			}
+			if fallback {
+				t.Errorf("LookupRuntimeDir() fallback = true, want false")
+			}
+		})
+	}
+}
+
+func TestLookupRuntimeDirFallback(t *testing.T) {
+	t.Setenv("XDG_RUNTIME_DIR", "")
+	t.Setenv("TMPDIR", t.TempDir())
+	t.Setenv("TMP", os.Getenv("TMPDIR")) // Windows.
+
+	want := filepath.Join(os.TempDir(), fallbackRuntimeDirName())
+	for range 2 { // Create, and then reuse.
+		got, fallback, err := LookupRuntimeDir()
+		if err != nil {
+			t.Fatalf("LookupRuntimeDir() error = %v", err)
+		}
+		if got != want {
+			t.Errorf("LookupRuntimeDir() = %q, want %q", got, want)
+		}
+		if !fallback {
+			t.Errorf("LookupRuntimeDir() fallback = false, want true")
+		}
+		if err := checkRuntimeDir(got); err != nil {
+			t.Errorf("checkRuntimeDir() error = %v", err)
+		}
+	}
+}
+
+func TestLookupRuntimeDirFallbackSymlink(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symbolic links require special privileges in Windows")
+	}
+
+	t.Setenv("XDG_RUNTIME_DIR", "")
+	t.Setenv("TMPDIR", t.TempDir())
+
+	target := t.TempDir()
+	if err := os.Symlink(target, filepath.Join(os.TempDir(), fallbackRuntimeDirName())); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, _, err := LookupRuntimeDir(); err == nil {
+		t.Errorf("LookupRuntimeDir() = %q, want error", got)
+	}
+}
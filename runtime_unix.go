@@ -0,0 +1,21 @@
+//go:build unix
+
+package xdg
+
+import (
+	"os"
+	"strconv"
+	"syscall"
+)
+
+// checkPermissions indicates whether Unix access modes are meaningful.
+const checkPermissions = true
+
+func fallbackRuntimeDirName() string {
+	return "xdg-runtime-" + strconv.Itoa(os.Getuid())
+}
+
+func ownedByCurrentUser(info os.FileInfo) bool {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	return ok && int(stat.Uid) == os.Getuid()
+}
@@ -0,0 +1,21 @@
+package xdg
+
+import (
+	"os"
+)
+
+// checkPermissions indicates whether Unix access modes are meaningful.
+// In Windows, [os.FileMode] reflects only the read-only attribute.
+const checkPermissions = false
+
+// fallbackRuntimeDirName doesn't need to be user-specific
+// in Windows, because [os.TempDir] is already user-specific.
+func fallbackRuntimeDirName() string {
+	return "xdg-runtime"
+}
+
+// ownedByCurrentUser always returns true in Windows, because
+// access to [os.TempDir] is already restricted by ACLs.
+func ownedByCurrentUser(_ os.FileInfo) bool {
+	return true
+}
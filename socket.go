@@ -0,0 +1,174 @@
+package xdg
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// ListenUnix creates a Unix domain socket for the given app, under the given
+// XDG base directory (typically [RuntimeDir] or [StateHome]), and listens on it.
+// It creates any parent directories if they don't exist yet.
+//
+// If the socket's path is too long for the operating system (sun_path is
+// limited to 104-108 bytes), this function transparently listens on a short
+// hashed path in [RuntimeDir] or its fallback replacement directory instead.
+// [DialUnix] uses the same logic to find it.
+//
+// If the socket file already exists but nobody is listening on it (e.g. the
+// previous owner crashed), this function removes it. If another process is
+// listening on it, this function returns an error that wraps [syscall.EADDRINUSE]
+// (or WSAEADDRINUSE on Windows).
+// Concurrent calls are serialized with an exclusive lock on a "<socket>.lock"
+// file next to the socket file, which is not removed.
+//
+// Note 1: this function normalizes the app name and ensures it doesn't
+// contain path elements, but the caller is responsible for input vetting.
+//
+// Note 2: the socketPath parameter must contain at least a filename, and may contain a prefix of
+// 0 or more path elements. This function ensures that it does not escape the app's directory.
+func ListenUnix(dirType func() (string, error), appName, socketPath string) (net.Listener, error) {
+	path, err := socketFilePath(dirType, appName, socketPath)
+	if err != nil {
+		return nil, err
+	}
+	return listenUnix(path)
+}
+
+// DialUnix connects to a Unix domain socket which was created by [ListenUnix]
+// with the same parameters, including its short path fallback logic.
+//
+// Note: this function normalizes its parameters like [ListenUnix].
+func DialUnix(ctx context.Context, dirType func() (string, error), appName, socketPath string) (net.Conn, error) {
+	path, err := socketFilePath(dirType, appName, socketPath)
+	if err != nil {
+		return nil, err
+	}
+	return dialUnix(ctx, path)
+}
+
+// socketFilePath resolves the path of a socket file like [CreateFilePath],
+// except that it does not create the file itself.
+func socketFilePath(dirType func() (string, error), appName, socketPath string) (string, error) {
+	if _, file := filepath.Split(socketPath); file == "" {
+		return "", errors.New("socket path must end with a file name")
+	}
+
+	socketPath = filepath.Clean(socketPath)
+	if socketPath == "." {
+		return "", errors.New("socket path is empty")
+	}
+
+	subpath, file := filepath.Split(socketPath)
+	path, err := CreateSubdir(dirType, appName, subpath)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(path, file), nil
+}
+
+func listenUnix(path string) (net.Listener, error) {
+	path, err := shortSocketPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// Serialize concurrent listeners, so one of them can't
+	// mistake a socket that another one is creating for a stale one.
+	l, err := lockSocket(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = l.Unlock() }()
+
+	ln, err := net.Listen("unix", path)
+	if err == nil || !errors.Is(err, errAddrInUse) {
+		return ln, err
+	}
+
+	// The socket file already exists: is it stale?
+	conn, dialErr := net.Dial("unix", path)
+	switch {
+	case dialErr == nil:
+		_ = conn.Close()
+		return nil, fmt.Errorf("another process is listening on %q: %w", path, errAddrInUse)
+	case !errors.Is(dialErr, errConnRefused):
+		return nil, fmt.Errorf("failed to check whether socket %q is stale: %w", path, dialErr)
+	}
+
+	if err := removeStaleSocket(path); err != nil {
+		return nil, err
+	}
+
+	return net.Listen("unix", path)
+}
+
+// lockSocket acquires an exclusive lock on a "<socket>.lock" file next to
+// the given socket file, and waits as long as necessary, like [LockFile].
+func lockSocket(path string) (*FileLock, error) {
+	lockPath := path + lockFileSuffix
+	f, err := os.OpenFile(lockPath, os.O_RDWR|os.O_CREATE, NewFilePermissions) //gosec:disable G304
+	if err != nil {
+		return nil, err
+	}
+
+	if err := waitLockFile(context.Background(), f, true); err != nil {
+		return nil, errors.Join(err, f.Close())
+	}
+
+	return &FileLock{Path: lockPath, f: f, exclusive: true}, nil
+}
+
+func dialUnix(ctx context.Context, path string) (net.Conn, error) {
+	path, err := shortSocketPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var d net.Dialer
+	return d.DialContext(ctx, "unix", path)
+}
+
+// maxSocketPathLen returns the maximum length of a Unix domain socket path,
+// excluding the terminating NUL byte that the sun_path field also contains.
+func maxSocketPathLen() int {
+	switch runtime.GOOS {
+	case "linux", "android", "windows":
+		return 107
+	default: // BSD flavors, including macOS.
+		return 103
+	}
+}
+
+// shortSocketPath returns the given socket path if it's short enough. Otherwise,
+// it returns a deterministic hashed path in [RuntimeDir] or its fallback
+// replacement, both of which are accessible only to the current user.
+func shortSocketPath(path string) (string, error) {
+	if len(path) <= maxSocketPathLen() {
+		return path, nil
+	}
+
+	sum := sha256.Sum256([]byte(path))
+	name := strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(sum[:10])) + ".sock"
+
+	dirs := []func() (string, error){RuntimeDir, fallbackRuntimeDir}
+	for _, dir := range dirs {
+		d, err := dir()
+		if err != nil {
+			continue
+		}
+		if short := filepath.Join(d, name); len(short) <= maxSocketPathLen() {
+			return short, nil
+		}
+	}
+
+	return "", fmt.Errorf("socket path is too long, and no short fallback is available: %q", path)
+}
@@ -0,0 +1,12 @@
+//go:build !windows
+
+package xdg
+
+import "syscall"
+
+const (
+	// errAddrInUse is the error of listening on a socket file that already exists.
+	errAddrInUse = syscall.EADDRINUSE
+	// errConnRefused is the error of dialing a socket file that nobody listens on.
+	errConnRefused = syscall.ECONNREFUSED
+)
@@ -0,0 +1,242 @@
+package xdg
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+)
+
+func TestListenUnix(t *testing.T) {
+	tests := []struct {
+		name    string
+		subdirs int
+	}{
+		{
+			name: "short_path",
+		},
+		{
+			name:    "long_path",
+			subdirs: 10,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runtimeDir := shortTempDir(t)
+			t.Setenv("XDG_RUNTIME_DIR", runtimeDir)
+
+			dir := filepath.Join(runtimeDir, strings.Repeat("0123456789"+string(filepath.Separator), tt.subdirs))
+			dirType := dirForTest(dir, nil)
+			socketPath := filepath.Join("sub", "app.sock")
+
+			ln, err := ListenUnix(dirType, "my_app", socketPath)
+			if err != nil {
+				if errors.Is(err, syscall.EAFNOSUPPORT) {
+					t.Skipf("Unix domain sockets are not supported: %v", err)
+				}
+				t.Fatalf("ListenUnix() error = %v", err)
+			}
+			defer ln.Close()
+
+			addr := ln.Addr().String()
+			if len(addr) > maxSocketPathLen() {
+				t.Errorf("ListenUnix() address length = %d, want <= %d", len(addr), maxSocketPathLen())
+			}
+			if tt.subdirs == 0 && addr != filepath.Join(dir, "my_app", socketPath) {
+				t.Errorf("ListenUnix() address = %q, want %q", addr, filepath.Join(dir, "my_app", socketPath))
+			}
+
+			if _, err := ListenUnix(dirType, "my_app", socketPath); !errors.Is(err, errAddrInUse) {
+				t.Errorf("second ListenUnix() error = %v, want %v", err, errAddrInUse)
+			}
+
+			conn, err := DialUnix(context.Background(), dirType, "my_app", socketPath)
+			if err != nil {
+				t.Fatalf("DialUnix() error = %v", err)
+			}
+			if err := conn.Close(); err != nil {
+				t.Error(err)
+			}
+
+			// Simulate a crashed listener.
+			ln.(*net.UnixListener).SetUnlinkOnClose(false)
+			if err := ln.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			ln, err = ListenUnix(dirType, "my_app", socketPath)
+			if err != nil {
+				t.Fatalf("ListenUnix() with stale socket error = %v", err)
+			}
+			if err := ln.Close(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+// TestListenUnixStaleSocket also checks that the platform-specific
+// errors match the ones that the standard library actually returns.
+func TestListenUnixStaleSocket(t *testing.T) {
+	dir := shortTempDir(t)
+	path := filepath.Join(dir, "my_app", "app.sock")
+	if err := os.MkdirAll(filepath.Dir(path), NewDirectoryPermissions); err != nil {
+		t.Fatal(err)
+	}
+
+	stale, err := net.Listen("unix", path)
+	if err != nil {
+		t.Skipf("Unix domain sockets are not supported: %v", err)
+	}
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	if err := stale.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := net.Listen("unix", path); !errors.Is(err, errAddrInUse) {
+		t.Errorf("net.Listen() with stale socket error = %v, want %v", err, errAddrInUse)
+	}
+	if _, err := net.Dial("unix", path); !errors.Is(err, errConnRefused) {
+		t.Errorf("net.Dial() with stale socket error = %v, want %v", err, errConnRefused)
+	}
+
+	ln, err := ListenUnix(dirForTest(dir, nil), "my_app", "app.sock")
+	if err != nil {
+		t.Fatalf("ListenUnix() with stale socket error = %v", err)
+	}
+	if err := ln.Close(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestListenUnixConcurrent(t *testing.T) {
+	dir := shortTempDir(t)
+	dirType := dirForTest(dir, nil)
+
+	// Start with a stale socket, which all the listeners try to recover.
+	ln, err := ListenUnix(dirType, "my_app", "app.sock")
+	if err != nil {
+		if errors.Is(err, syscall.EAFNOSUPPORT) {
+			t.Skipf("Unix domain sockets are not supported: %v", err)
+		}
+		t.Fatalf("ListenUnix() error = %v", err)
+	}
+	ln.(*net.UnixListener).SetUnlinkOnClose(false)
+	if err := ln.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	const n = 20
+	results := make(chan net.Listener, n)
+	errs := make(chan error, n)
+	var wg sync.WaitGroup
+	for range n {
+		wg.Go(func() {
+			ln, err := ListenUnix(dirType, "my_app", "app.sock")
+			if err != nil {
+				errs <- err
+				return
+			}
+			results <- ln
+		})
+	}
+	wg.Wait()
+	close(results)
+	close(errs)
+
+	if len(results) != 1 {
+		t.Fatalf("concurrent ListenUnix() succeeded %d times, want 1", len(results))
+	}
+	for err := range errs {
+		if !errors.Is(err, errAddrInUse) {
+			t.Errorf("concurrent ListenUnix() error = %v, want %v", err, errAddrInUse)
+		}
+	}
+
+	ln = <-results
+	defer ln.Close()
+
+	conn, err := DialUnix(context.Background(), dirType, "my_app", "app.sock")
+	if err != nil {
+		t.Fatalf("DialUnix() to the winning listener error = %v", err)
+	}
+	if err := conn.Close(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestListenUnixNotSocket(t *testing.T) {
+	dir := shortTempDir(t)
+
+	path := filepath.Join(dir, "my_app", "app.sock")
+	if err := os.MkdirAll(filepath.Dir(path), NewDirectoryPermissions); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("data"), NewFilePermissions); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := ListenUnix(dirForTest(dir, nil), "my_app", "app.sock"); err == nil {
+		t.Error("ListenUnix() error = nil, want error")
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("ListenUnix() removed a regular file: %v", err)
+	}
+}
+
+func TestSocketFilePath(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name       string
+		appName    string
+		socketPath string
+		wantErr    bool
+	}{
+		{
+			name:       "valid",
+			appName:    "my_app",
+			socketPath: "app.sock",
+		},
+		{
+			name:       "empty_app_name",
+			socketPath: "app.sock",
+			wantErr:    true,
+		},
+		{
+			name:       "empty_socket_path",
+			appName:    "my_app",
+			socketPath: "",
+			wantErr:    true,
+		},
+		{
+			name:       "directory_socket_path",
+			appName:    "my_app",
+			socketPath: "sub/",
+			wantErr:    true,
+		},
+		{
+			name:       "escaping_socket_path",
+			appName:    "my_app",
+			socketPath: "../app.sock",
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := socketFilePath(dirForTest(dir, nil), tt.appName, tt.socketPath)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("socketFilePath() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if want := filepath.Join(dir, tt.appName, tt.socketPath); !tt.wantErr && got != want {
+				t.Errorf("socketFilePath() = %q, want %q", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,10 @@
+package xdg
+
+import "golang.org/x/sys/windows"
+
+const (
+	// errAddrInUse is the error of listening on a socket file that already exists.
+	errAddrInUse = windows.WSAEADDRINUSE
+	// errConnRefused is the error of dialing a socket file that nobody listens on.
+	errConnRefused = windows.WSAECONNREFUSED
+)
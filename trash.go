@@ -0,0 +1,476 @@
+package xdg
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	trashInfoSuffix  = ".trashinfo"
+	trashInfoHeader  = "[Trash Info]"
+	trashDateFormat  = "2006-01-02T15:04:05"
+	maxTrashAttempts = 1000
+)
+
+// TrashedFile is a file in a trash directory, which was
+// trashed by [Trash], or found by [ListTrash].
+type TrashedFile struct {
+	// Name is the file's unique name in the trash directory,
+	// which may differ from its original name due to collisions.
+	Name string
+	// OriginalPath is the absolute path of the file before it was trashed.
+	OriginalPath string
+	// DeletionDate is the time when the file was trashed, in local time.
+	DeletionDate time.Time
+	// TrashDir is the trash directory that contains the file, e.g. "$XDG_DATA_HOME/Trash".
+	TrashDir string
+}
+
+// Trash moves a file or directory to the trash, according to the
+// FreeDesktop.org Trash specification (https://specifications.freedesktop.org/trash-spec/).
+//
+// Files in the same filesystem as [DataHome] are moved to the user's home trash
+// directory: "$XDG_DATA_HOME/Trash". Files in other filesystems are moved to
+// the trash directory in the top directory of their filesystem: "$topdir/.Trash/$uid"
+// if an administrator has set up "$topdir/.Trash", or "$topdir/.Trash-$uid"
+// otherwise. This is supported only in Unix. Files are never copied across
+// filesystems. Symbolic links are trashed, not their targets.
+func Trash(path string) (*TrashedFile, error) {
+	return defaultResolver.Trash(path)
+}
+
+// Trash is like the package-level [Trash], but uses r's environment.
+func (r *Resolver) Trash(path string) (*TrashedFile, error) {
+	if err := r.checkHost(); err != nil {
+		return nil, err
+	}
+
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Lstat(path); err != nil {
+		return nil, err
+	}
+
+	trashDir, topdir, err := r.trashDirFor(path)
+	if err != nil {
+		return nil, err
+	}
+
+	infoPath := path
+	if topdir != "" {
+		if infoPath, err = filepath.Rel(topdir, path); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	name, err := reserveTrashName(trashDir, filepath.Base(path), trashInfo(infoPath, now))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.Rename(path, filepath.Join(trashDir, "files", name)); err != nil {
+		_ = os.Remove(filepath.Join(trashDir, "info", name+trashInfoSuffix))
+		return nil, err
+	}
+
+	return &TrashedFile{
+		Name:         name,
+		OriginalPath: path,
+		DeletionDate: now.Truncate(time.Second),
+		TrashDir:     trashDir,
+	}, nil
+}
+
+// ListTrash returns the files in the user's home trash directory, and in the trash
+// directories of the given top directories (i.e. mount points) of other filesystems.
+// Entries without a valid ".trashinfo" file, or without a trashed file, are skipped.
+func ListTrash(topdirs ...string) ([]TrashedFile, error) {
+	return defaultResolver.ListTrash(topdirs...)
+}
+
+// ListTrash is like the package-level [ListTrash], but uses r's environment.
+func (r *Resolver) ListTrash(topdirs ...string) ([]TrashedFile, error) {
+	dirs, err := r.trashDirs(topdirs)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []TrashedFile
+	for _, d := range dirs {
+		found, err := listTrashDir(d.path, d.topdir)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, found...)
+	}
+
+	return files, nil
+}
+
+// RestoreTrash moves a trashed file back to its original path, and recreates
+// any missing parent directories. It fails if the original path already exists.
+func RestoreTrash(f TrashedFile) error {
+	if f.Name == "" || strings.ContainsAny(f.Name, `/\`) || f.Name == "." || f.Name == ".." {
+		return fmt.Errorf("invalid trashed file name %q", f.Name)
+	}
+	if !filepath.IsAbs(f.OriginalPath) {
+		return fmt.Errorf("original path of trashed file is not absolute: %q", f.OriginalPath)
+	}
+
+	if _, err := os.Lstat(f.OriginalPath); err == nil {
+		return &os.PathError{Op: "restore", Path: f.OriginalPath, Err: fs.ErrExist}
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(f.OriginalPath), NewDirectoryPermissions); err != nil {
+		return err
+	}
+	if err := os.Rename(filepath.Join(f.TrashDir, "files", f.Name), f.OriginalPath); err != nil {
+		return err
+	}
+
+	return os.Remove(filepath.Join(f.TrashDir, "info", f.Name+trashInfoSuffix))
+}
+
+// EmptyTrash permanently removes all the files in the user's home trash directory,
+// and in the trash directories of the given top directories (see [ListTrash]).
+func EmptyTrash(topdirs ...string) error {
+	return defaultResolver.EmptyTrash(topdirs...)
+}
+
+// EmptyTrash is like the package-level [EmptyTrash], but uses r's environment.
+func (r *Resolver) EmptyTrash(topdirs ...string) error {
+	dirs, err := r.trashDirs(topdirs)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, d := range dirs {
+		// Remove trashed files before their info files, so
+		// an interruption doesn't leave files without info.
+		for _, sub := range []string{"files", "info"} {
+			entries, err := os.ReadDir(filepath.Join(d.path, sub))
+			if err != nil {
+				if !errors.Is(err, fs.ErrNotExist) {
+					errs = append(errs, err)
+				}
+				continue
+			}
+			for _, e := range entries {
+				errs = append(errs, os.RemoveAll(filepath.Join(d.path, sub, e.Name())))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// homeTrashDir returns the path of the user's home trash directory.
+func (r *Resolver) homeTrashDir() (string, error) {
+	path, err := r.DataHome()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(path, "Trash"), nil
+}
+
+// trashDirFor returns the trash directory for the given absolute path, and creates it
+// if necessary. If it's not the home trash, it also returns the filesystem's top directory.
+func (r *Resolver) trashDirFor(path string) (string, string, error) {
+	home, err := r.homeTrashDir()
+	if err != nil {
+		return "", "", err
+	}
+	if err := makeTrashDir(home); err != nil {
+		return "", "", err
+	}
+
+	dir := filepath.Dir(path)
+	same, err := sameDevice(dir, home)
+	if err != nil {
+		return "", "", err
+	}
+	if same {
+		return home, "", nil
+	}
+
+	if !topdirTrashSupported {
+		return "", "", fmt.Errorf("can't trash %q: it's not in the same filesystem as %q", path, home)
+	}
+
+	topdir, err := mountPoint(dir)
+	if err != nil {
+		return "", "", err
+	}
+
+	trashDir, err := topdirTrashDir(topdir, true)
+	if err != nil {
+		return "", "", fmt.Errorf("can't trash %q: %w", path, err)
+	}
+	return trashDir, topdir, nil
+}
+
+// topdirTrashDir returns the trash directory of the current user in the given top
+// directory, if there is a valid one, or if it can create one. See section
+// "Trash directories" in the spec for details about these validations.
+func topdirTrashDir(topdir string, create bool) (string, error) {
+	uid := strconv.Itoa(os.Getuid())
+
+	shared := filepath.Join(topdir, ".Trash")
+	if info, err := os.Lstat(shared); err == nil && info.IsDir() && info.Mode()&os.ModeSticky != 0 {
+		path := filepath.Join(shared, uid)
+		if !create {
+			return path, nil
+		}
+		if err := makeTrashDir(path); err == nil {
+			return path, nil
+		}
+	}
+
+	path := filepath.Join(topdir, ".Trash-"+uid)
+	if !create {
+		return path, nil
+	}
+	if err := makeTrashDir(path); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// makeTrashDir creates a trash directory and its subdirectories, if they don't exist yet,
+// and ensures that it's a real directory (not a symbolic link) owned by the current user.
+func makeTrashDir(path string) error {
+	if err := os.MkdirAll(path, NewDirectoryPermissions); err != nil {
+		return err
+	}
+
+	info, err := os.Lstat(path)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() || !ownedByCurrentUser(info) {
+		return fmt.Errorf("invalid trash directory %q", path)
+	}
+
+	for _, sub := range []string{"files", "info"} {
+		err := os.Mkdir(filepath.Join(path, sub), NewDirectoryPermissions)
+		if err != nil && !errors.Is(err, fs.ErrExist) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type trashDir struct {
+	path   string
+	topdir string // Empty for the home trash.
+}
+
+// trashDirs returns the home trash directory, and
+// the existing trash directories of the given top directories.
+func (r *Resolver) trashDirs(topdirs []string) ([]trashDir, error) {
+	if err := r.checkHost(); err != nil {
+		return nil, err
+	}
+
+	home, err := r.homeTrashDir()
+	if err != nil {
+		return nil, err
+	}
+
+	dirs := []trashDir{{path: home}}
+	if !topdirTrashSupported {
+		return dirs, nil
+	}
+
+	for _, topdir := range topdirs {
+		topdir, err := filepath.Abs(topdir)
+		if err != nil {
+			return nil, err
+		}
+
+		path, err := topdirTrashDir(topdir, false)
+		if err != nil {
+			return nil, err
+		}
+		if info, err := os.Lstat(path); err == nil && info.IsDir() {
+			dirs = append(dirs, trashDir{path: path, topdir: topdir})
+		}
+	}
+
+	return dirs, nil
+}
+
+// reserveTrashName reserves a unique name in the given trash directory, by creating its
+// ".trashinfo" file atomically. If the name is taken, it adds a numeric suffix to it.
+func reserveTrashName(trashDir, name string, info []byte) (string, error) {
+	ext := filepath.Ext(name)
+	stem := strings.TrimSuffix(name, ext)
+	if stem == "" { // E.g. ".bashrc".
+		stem, ext = name, ""
+	}
+
+	for i := 1; i <= maxTrashAttempts; i++ {
+		candidate := name
+		if i > 1 {
+			candidate = fmt.Sprintf("%s.%d%s", stem, i, ext)
+		}
+
+		infoPath := filepath.Join(trashDir, "info", candidate+trashInfoSuffix)
+		f, err := os.OpenFile(infoPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, NewFilePermissions) //gosec:disable G304
+		if errors.Is(err, fs.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+
+		// A file without info, e.g. after an interruption.
+		if _, err := os.Lstat(filepath.Join(trashDir, "files", candidate)); err == nil {
+			_ = f.Close()
+			_ = os.Remove(infoPath)
+			continue
+		}
+
+		_, err = f.Write(info)
+		err = errors.Join(err, f.Close())
+		if err != nil {
+			_ = os.Remove(infoPath)
+			return "", err
+		}
+
+		return candidate, nil
+	}
+
+	return "", fmt.Errorf("too many files named %q in trash directory %q", name, trashDir)
+}
+
+// trashInfo returns the content of a ".trashinfo" file.
+func trashInfo(path string, deletionDate time.Time) []byte {
+	u := &url.URL{Path: filepath.ToSlash(path)}
+	date := deletionDate.Format(trashDateFormat)
+	return fmt.Appendf(nil, "%s\nPath=%s\nDeletionDate=%s\n", trashInfoHeader, u.EscapedPath(), date)
+}
+
+// listTrashDir returns the files in the given trash directory.
+func listTrashDir(path, topdir string) ([]TrashedFile, error) {
+	entries, err := os.ReadDir(filepath.Join(path, "info"))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var files []TrashedFile
+	for _, e := range entries {
+		name, ok := strings.CutSuffix(e.Name(), trashInfoSuffix)
+		if !ok || !e.Type().IsRegular() {
+			continue
+		}
+		if _, err := os.Lstat(filepath.Join(path, "files", name)); err != nil {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(path, "info", e.Name())) //gosec:disable G304
+		if err != nil {
+			return nil, err
+		}
+
+		f, ok := parseTrashInfo(data, topdir)
+		if !ok {
+			continue
+		}
+
+		f.Name, f.TrashDir = name, path
+		files = append(files, f)
+	}
+
+	return files, nil
+}
+
+// parseTrashInfo parses the content of a ".trashinfo" file. Relative
+// paths are resolved relative to the given top directory, if it's set.
+func parseTrashInfo(data []byte, topdir string) (TrashedFile, bool) {
+	var f TrashedFile
+	inGroup := false
+
+	s := bufio.NewScanner(bytes.NewReader(data))
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if strings.HasPrefix(line, "[") {
+			inGroup = line == trashInfoHeader
+			continue
+		}
+		if !inGroup {
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+
+		switch strings.TrimSpace(key) {
+		case "Path":
+			p, err := url.PathUnescape(strings.TrimSpace(value))
+			if err != nil {
+				return f, false
+			}
+			f.OriginalPath = filepath.FromSlash(p)
+		case "DeletionDate":
+			t, err := time.ParseInLocation(trashDateFormat, strings.TrimSpace(value), time.Local)
+			if err != nil {
+				return f, false
+			}
+			f.DeletionDate = t
+		}
+	}
+
+	if f.OriginalPath == "" {
+		return f, false
+	}
+	if !filepath.IsAbs(f.OriginalPath) {
+		if topdir == "" {
+			return f, false
+		}
+		f.OriginalPath = filepath.Join(topdir, f.OriginalPath)
+	}
+
+	return f, true
+}
+
+// mountPoint returns the top directory of the filesystem that contains the given directory.
+func mountPoint(dir string) (string, error) {
+	for {
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return dir, nil
+		}
+
+		same, err := sameDevice(dir, parent)
+		if err != nil {
+			return "", err
+		}
+		if !same {
+			return dir, nil
+		}
+
+		dir = parent
+	}
+}
@@ -0,0 +1,186 @@
+package xdg
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTrash(t *testing.T) {
+	dir := t.TempDir()
+	r := &Resolver{Getenv: mapEnv(map[string]string{"XDG_DATA_HOME": filepath.Join(dir, "data")})}
+
+	path := filepath.Join(dir, "work", "my file.txt")
+	if err := os.MkdirAll(filepath.Dir(path), NewDirectoryPermissions); err != nil {
+		t.Fatal(err)
+	}
+
+	var trashed []*TrashedFile
+	for _, content := range []string{"first", "second"} {
+		if err := os.WriteFile(path, []byte(content), NewFilePermissions); err != nil {
+			t.Fatal(err)
+		}
+		f, err := r.Trash(path)
+		if err != nil {
+			t.Fatalf("Trash() error = %v", err)
+		}
+		trashed = append(trashed, f)
+	}
+
+	trashDir := filepath.Join(dir, "data", "Trash")
+	if trashed[0].Name != "my file.txt" || trashed[1].Name != "my file.2.txt" {
+		t.Errorf("Trash() names = %q, %q, want %q, %q",
+			trashed[0].Name, trashed[1].Name, "my file.txt", "my file.2.txt")
+	}
+	if trashed[0].TrashDir != trashDir {
+		t.Errorf("Trash() TrashDir = %q, want %q", trashed[0].TrashDir, trashDir)
+	}
+
+	info, err := os.ReadFile(filepath.Join(trashDir, "info", "my file.txt"+trashInfoSuffix))
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantPath := "Path=" + strings.ReplaceAll(filepath.ToSlash(path), " ", "%20") + "\n"
+	if !strings.HasPrefix(string(info), trashInfoHeader+"\n") || !strings.Contains(string(info), wantPath) {
+		t.Errorf(".trashinfo content = %q, want %q", info, wantPath)
+	}
+
+	files, err := r.ListTrash()
+	if err != nil {
+		t.Fatalf("ListTrash() error = %v", err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("ListTrash() = %v, want 2 files", files)
+	}
+	for _, f := range files {
+		if f.OriginalPath != path {
+			t.Errorf("ListTrash() OriginalPath = %q, want %q", f.OriginalPath, path)
+		}
+		if time.Since(f.DeletionDate) > time.Minute {
+			t.Errorf("ListTrash() DeletionDate = %v, want now", f.DeletionDate)
+		}
+	}
+
+	if err := RestoreTrash(*trashed[1]); err != nil {
+		t.Fatalf("RestoreTrash() error = %v", err)
+	}
+	if got, _ := os.ReadFile(path); string(got) != "second" {
+		t.Errorf("restored file = %q, want %q", got, "second")
+	}
+	if err := RestoreTrash(*trashed[0]); !errors.Is(err, fs.ErrExist) {
+		t.Errorf("RestoreTrash() over existing file error = %v, want %v", err, fs.ErrExist)
+	}
+
+	if err := r.EmptyTrash(); err != nil {
+		t.Fatalf("EmptyTrash() error = %v", err)
+	}
+	if files, err := r.ListTrash(); err != nil || len(files) != 0 {
+		t.Errorf("ListTrash() after EmptyTrash() = %v, %v, want none", files, err)
+	}
+	for _, sub := range []string{"files", "info"} {
+		if entries, _ := os.ReadDir(filepath.Join(trashDir, sub)); len(entries) != 0 {
+			t.Errorf("EmptyTrash() left %d entries in %q", len(entries), sub)
+		}
+	}
+}
+
+func TestTopdirTrashDir(t *testing.T) {
+	if !topdirTrashSupported {
+		t.Skip("per-filesystem trash directories are not supported")
+	}
+
+	uid := strconv.Itoa(os.Getuid())
+	topdir := t.TempDir()
+
+	got, err := topdirTrashDir(topdir, true)
+	if err != nil {
+		t.Fatalf("topdirTrashDir() error = %v", err)
+	}
+	if want := filepath.Join(topdir, ".Trash-"+uid); got != want {
+		t.Errorf("topdirTrashDir() = %q, want %q", got, want)
+	}
+
+	// An administrator-created shared trash directory, with the sticky bit.
+	shared := filepath.Join(topdir, ".Trash")
+	if err := os.Mkdir(shared, 0o777); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(shared, 0o777|os.ModeSticky); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err = topdirTrashDir(topdir, true)
+	if err != nil {
+		t.Fatalf("topdirTrashDir() error = %v", err)
+	}
+	if want := filepath.Join(shared, uid); got != want {
+		t.Errorf("topdirTrashDir() with shared trash = %q, want %q", got, want)
+	}
+
+	files, err := listTrashDir(got, topdir)
+	if err != nil || len(files) != 0 {
+		t.Errorf("listTrashDir() = %v, %v, want none", files, err)
+	}
+}
+
+func TestParseTrashInfo(t *testing.T) {
+	abs := filepath.Join(t.TempDir(), "a b%.txt")
+	date := time.Date(2026, 10, 18, 12, 30, 0, 0, time.Local)
+
+	tests := []struct {
+		name     string
+		data     string
+		topdir   string
+		wantPath string
+		wantOK   bool
+	}{
+		{
+			name:     "round_trip",
+			data:     string(trashInfo(abs, date)),
+			wantPath: abs,
+			wantOK:   true,
+		},
+		{
+			name:     "relative_path_in_topdir",
+			data:     "[Trash Info]\nPath=dir/file\nDeletionDate=2026-10-18T12:30:00\n",
+			topdir:   filepath.Join(string(filepath.Separator)+"mnt", "usb"),
+			wantPath: filepath.Join(string(filepath.Separator)+"mnt", "usb", "dir", "file"),
+			wantOK:   true,
+		},
+		{
+			name: "relative_path_in_home_trash",
+			data: "[Trash Info]\nPath=dir/file\nDeletionDate=2026-10-18T12:30:00\n",
+		},
+		{
+			name: "wrong_group",
+			data: "[Desktop Entry]\nPath=/file\n",
+		},
+		{
+			name: "invalid_date",
+			data: "[Trash Info]\nPath=/file\nDeletionDate=yesterday\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseTrashInfo([]byte(tt.data), tt.topdir)
+			if ok != tt.wantOK {
+				t.Fatalf("parseTrashInfo() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+			if got.OriginalPath != tt.wantPath {
+				t.Errorf("parseTrashInfo() OriginalPath = %q, want %q", got.OriginalPath, tt.wantPath)
+			}
+			if !got.DeletionDate.Equal(date) {
+				t.Errorf("parseTrashInfo() DeletionDate = %v, want %v", got.DeletionDate, date)
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+//go:build unix
+
+package xdg
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// topdirTrashSupported indicates whether files in other filesystems can be
+// trashed in per-filesystem trash directories, which are identified by UID.
+const topdirTrashSupported = true
+
+// sameDevice checks whether two paths are in the same filesystem.
+func sameDevice(a, b string) (bool, error) {
+	infoA, err := os.Stat(a)
+	if err != nil {
+		return false, err
+	}
+	infoB, err := os.Stat(b)
+	if err != nil {
+		return false, err
+	}
+
+	statA, okA := infoA.Sys().(*syscall.Stat_t)
+	statB, okB := infoB.Sys().(*syscall.Stat_t)
+	if !okA || !okB {
+		return false, fmt.Errorf("can't determine the filesystems of %q and %q", a, b)
+	}
+
+	return statA.Dev == statB.Dev, nil
+}
@@ -0,0 +1,24 @@
+package xdg
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// topdirTrashSupported indicates whether files in other filesystems can be
+// trashed in per-filesystem trash directories, which are identified by UID.
+const topdirTrashSupported = false
+
+// sameDevice checks whether two paths are in the same volume.
+func sameDevice(a, b string) (bool, error) {
+	a, err := filepath.Abs(a)
+	if err != nil {
+		return false, err
+	}
+	b, err = filepath.Abs(b)
+	if err != nil {
+		return false, err
+	}
+
+	return strings.EqualFold(filepath.VolumeName(a), filepath.VolumeName(b)), nil
+}
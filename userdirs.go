@@ -0,0 +1,378 @@
+package xdg
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	userDirsFileName = "user-dirs.dirs"
+)
+
+// Well-known user directory names, as used in the "XDG_<name>_DIR"
+// keys of the [xdg-user-dirs] configuration file.
+//
+// [xdg-user-dirs]: https://www.freedesktop.org/wiki/Software/xdg-user-dirs/
+const (
+	UserDirDesktop     = "DESKTOP"
+	UserDirDocuments   = "DOCUMENTS"
+	UserDirDownload    = "DOWNLOAD"
+	UserDirMusic       = "MUSIC"
+	UserDirPictures    = "PICTURES"
+	UserDirPublicShare = "PUBLICSHARE"
+	UserDirTemplates   = "TEMPLATES"
+	UserDirVideos      = "VIDEOS"
+)
+
+// DesktopDir returns the absolute path of the user's desktop directory.
+// See [UserDir] for details.
+func DesktopDir() (string, error) {
+	return UserDir(UserDirDesktop)
+}
+
+// DocumentsDir returns the absolute path of the user's documents directory.
+// See [UserDir] for details.
+func DocumentsDir() (string, error) {
+	return UserDir(UserDirDocuments)
+}
+
+// DownloadDir returns the absolute path of the user's downloads directory.
+// See [UserDir] for details.
+func DownloadDir() (string, error) {
+	return UserDir(UserDirDownload)
+}
+
+// MusicDir returns the absolute path of the user's music directory.
+// See [UserDir] for details.
+func MusicDir() (string, error) {
+	return UserDir(UserDirMusic)
+}
+
+// PicturesDir returns the absolute path of the user's pictures directory.
+// See [UserDir] for details.
+func PicturesDir() (string, error) {
+	return UserDir(UserDirPictures)
+}
+
+// PublicShareDir returns the absolute path of the user's public/shared
+// directory. See [UserDir] for details.
+func PublicShareDir() (string, error) {
+	return UserDir(UserDirPublicShare)
+}
+
+// TemplatesDir returns the absolute path of the user's templates directory.
+// See [UserDir] for details.
+func TemplatesDir() (string, error) {
+	return UserDir(UserDirTemplates)
+}
+
+// VideosDir returns the absolute path of the user's videos directory.
+// See [UserDir] for details.
+func VideosDir() (string, error) {
+	return UserDir(UserDirVideos)
+}
+
+// UserDir returns the absolute path of a well-known user directory,
+// such as [UserDirDownload], in the same way as [xdg-user-dirs]:
+//   - The "XDG_<name>_DIR" environment variable, if it's set,
+//   - Otherwise, the "XDG_<name>_DIR" entry in the "user-dirs.dirs"
+//     file under [ConfigHome], if it exists,
+//   - Otherwise, an OS-specific default: in Unix this is [HomeDir]
+//     (or "$HOME/Desktop" for the desktop directory), and in macOS
+//     and Windows this is the corresponding standard user folder.
+//
+// Nested environment variables (e.g. "$HOME") in the "XDG_<name>_DIR" environment
+// variable are auto-expanded. In the file, only a leading "$HOME" is substituted,
+// like in xdg-user-dirs, so any other "$" is part of the path. Note that this
+// function does not check whether the directory exists.
+//
+// [xdg-user-dirs]: https://www.freedesktop.org/wiki/Software/xdg-user-dirs/
+func UserDir(name string) (string, error) {
+	return defaultResolver.UserDir(name)
+}
+
+// UserDir is like the package-level [UserDir], but uses r's environment.
+func (r *Resolver) UserDir(name string) (string, error) {
+	name = strings.ToUpper(name)
+	key := userDirKey(name)
+
+	path := r.expand(r.getenv(key))
+	if path == "" && r.checkHost() == nil {
+		entries, err := r.readUserDirs()
+		if err != nil {
+			return "", err
+		}
+		if path, err = r.userDirsPath(entries[key]); err != nil {
+			return "", err
+		}
+	}
+
+	if path == "" {
+		home, err := r.home()
+		if err != nil {
+			return "", err
+		}
+		if r.Defaults.UserDir != nil {
+			path = r.Defaults.UserDir(home, name)
+		} else {
+			path = r.platformDefaults().UserDir(home, name)
+		}
+	}
+
+	if r.syntax().isAbs(path) {
+		return path, nil
+	}
+
+	return "", fmt.Errorf("path in %s is relative: %q", key, path)
+}
+
+func userDirKey(name string) string {
+	return "XDG_" + name + "_DIR"
+}
+
+// SetUserDir changes the path of a well-known user directory, such as
+// [UserDirDownload], in the "user-dirs.dirs" file under [ConfigHome],
+// in the same way as "xdg-user-dirs-update --set".
+//
+// The file is rewritten atomically, and it retains all its comments and
+// other entries. The path must be absolute, and it's written in the
+// "$HOME/..." form if it's under [HomeDir]. If createDir is true, this
+// function also creates the directory if it doesn't exist yet.
+//
+// Note that the "XDG_<name>_DIR" environment variable, if it's
+// set, still takes precedence over the file in [UserDir].
+func SetUserDir(name, path string, createDir bool) error {
+	return defaultResolver.SetUserDir(name, path, createDir)
+}
+
+// SetUserDir is like the package-level [SetUserDir], but uses r's environment.
+func (r *Resolver) SetUserDir(name, path string, createDir bool) error {
+	if err := r.checkHost(); err != nil {
+		return err
+	}
+
+	name = strings.ToUpper(name)
+	key := userDirKey(name)
+
+	path = filepath.Clean(path)
+	if !filepath.IsAbs(path) {
+		return fmt.Errorf("path for %s is relative: %q", key, path)
+	}
+
+	if createDir {
+		if err := os.MkdirAll(path, NewDirectoryPermissions); err != nil {
+			return err
+		}
+	}
+
+	configHome, err := r.ConfigHome()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(configHome, NewDirectoryPermissions); err != nil {
+		return err
+	}
+
+	filePath := filepath.Join(configHome, userDirsFileName)
+	content, err := os.ReadFile(filePath) //gosec:disable G304
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	perm := os.FileMode(NewFilePermissions)
+	if info, err := os.Stat(filePath); err == nil {
+		perm = info.Mode().Perm()
+	}
+
+	value, err := r.userDirsValue(path)
+	if err != nil {
+		return err
+	}
+
+	content = updateUserDirs(content, key, value)
+	return writeFileAtomic(configHome, userDirsFileName, content, perm)
+}
+
+// updateUserDirs replaces the first assignment of the given key in the content
+// of a "user-dirs.dirs" file, and removes any subsequent assignments of it. If
+// the key isn't assigned anywhere, this function appends it to the content.
+func updateUserDirs(content []byte, key, value string) []byte {
+	var lines []string
+	if len(content) > 0 {
+		lines = strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+	}
+
+	found := false
+	updated := make([]string, 0, len(lines)+1)
+	for _, line := range lines {
+		if k, _, ok := parseUserDirsLine(line); ok && k == key {
+			if found {
+				continue
+			}
+			line = key + "=" + value
+			found = true
+		}
+		updated = append(updated, line)
+	}
+
+	if !found {
+		updated = append(updated, key+"="+value)
+	}
+
+	return []byte(strings.Join(updated, "\n") + "\n")
+}
+
+// userDirsValue returns the double-quoted representation of the given absolute
+// path in a "user-dirs.dirs" file: "$HOME/..." if it's under the home directory,
+// or the absolute path otherwise. This is the only format allowed by xdg-user-dirs.
+func (r *Resolver) userDirsValue(path string) (string, error) {
+	home, err := r.home()
+	if err != nil {
+		return "", err
+	}
+
+	prefix := ""
+	rel, err := filepath.Rel(home, path)
+	switch {
+	case err != nil:
+	case rel == ".":
+		prefix, path = "$HOME", ""
+	case filepath.IsLocal(rel):
+		prefix, path = "$HOME/", filepath.ToSlash(rel)
+	}
+
+	escaper := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "$", `\$`, "`", "\\`")
+	return `"` + prefix + escaper.Replace(path) + `"`, nil
+}
+
+// userDirsEntry is the value of an entry in a "user-dirs.dirs" file.
+type userDirsEntry struct {
+	// path is the unquoted path, relative to the home directory if underHome is true.
+	path      string
+	underHome bool
+}
+
+// readUserDirs reads all the "XDG_<name>_DIR" entries from the "user-dirs.dirs"
+// file under [ConfigHome]. The values are unquoted, but not expanded yet.
+// A missing file is not considered an error.
+func (r *Resolver) readUserDirs() (map[string]userDirsEntry, error) {
+	path, err := r.ConfigHome()
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(filepath.Join(path, userDirsFileName)) //gosec:disable G304
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return map[string]userDirsEntry{}, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	entries := map[string]userDirsEntry{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if key, value, ok := parseUserDirsLine(scanner.Text()); ok {
+			entries[key] = value
+		}
+	}
+
+	return entries, scanner.Err()
+}
+
+// userDirsPath returns the absolute path of an entry in a "user-dirs.dirs"
+// file, or an empty string if the entry is empty.
+func (r *Resolver) userDirsPath(e userDirsEntry) (string, error) {
+	if !e.underHome {
+		if e.path == "" {
+			return "", nil
+		}
+		return filepath.Clean(e.path), nil
+	}
+
+	home, err := r.home()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, e.path), nil
+}
+
+// parseUserDirsLine parses a single line in a "user-dirs.dirs" file, which
+// is a shell script containing "XDG_<name>_DIR=value" variable assignments.
+// It ignores empty lines, comments, and anything that isn't such an assignment.
+//
+// Like xdg-user-dirs, it substitutes only a leading "$HOME" (before unquoting,
+// so an escaped "\$HOME" is literal), and not any other shell variables.
+func parseUserDirsLine(line string) (key string, e userDirsEntry, ok bool) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return "", userDirsEntry{}, false
+	}
+
+	key, value, ok := strings.Cut(line, "=")
+	if !ok || !strings.HasPrefix(key, "XDG_") || !strings.HasSuffix(key, "_DIR") {
+		return "", userDirsEntry{}, false
+	}
+
+	quote := ""
+	if strings.HasPrefix(value, `"`) {
+		quote, value = `"`, value[1:]
+	}
+	after, found := strings.CutPrefix(value, "$HOME")
+	if found && (after == "" || strings.IndexByte("/\"' \t", after[0]) >= 0) {
+		e.underHome, value = true, after
+	}
+
+	if e.path, ok = shellUnquote(quote + value); !ok {
+		return "", userDirsEntry{}, false
+	}
+
+	return key, e, true
+}
+
+// shellUnquote removes shell-style quoting from the given value:
+// single quotes, double quotes, and backslash escapes. It stops at the
+// first unquoted whitespace, and returns false if a quote is unterminated.
+func shellUnquote(s string) (string, bool) {
+	var sb strings.Builder
+	var quote rune
+
+	runes := []rune(s)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				sb.WriteRune(r)
+			}
+		case quote == '"':
+			switch {
+			case r == '"':
+				quote = 0
+			case r == '\\' && i+1 < len(runes) && strings.ContainsRune(`$"\`+"`", runes[i+1]):
+				i++
+				sb.WriteRune(runes[i])
+			default:
+				sb.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+		case r == '\\' && i+1 < len(runes):
+			i++
+			sb.WriteRune(runes[i])
+		case r == ' ' || r == '\t':
+			return sb.String(), true
+		default:
+			sb.WriteRune(r)
+		}
+	}
+
+	return sb.String(), quote == 0
+}
@@ -0,0 +1,329 @@
+package xdg
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestUserDir(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	config := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", config)
+
+	content := `# This file is written by xdg-user-dirs-update
+XDG_DESKTOP_DIR="$HOME/Desktop"
+XDG_DOWNLOAD_DIR="$HOME/My \"Downloads\""
+XDG_MUSIC_DIR='/mnt/music'
+XDG_PICTURES_DIR=relative/path
+XDG_VIDEOS_DIR="$HOME/Videos" # Trailing comment.
+`
+	if err := os.WriteFile(filepath.Join(config, userDirsFileName), []byte(content), NewFilePermissions); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		dir     string
+		env     string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "double_quotes",
+			dir:  UserDirDesktop,
+			want: filepath.Join(home, "Desktop"),
+		},
+		{
+			name: "escaped_quotes",
+			dir:  UserDirDownload,
+			want: filepath.Join(home, `My "Downloads"`),
+		},
+		{
+			name: "single_quotes",
+			dir:  UserDirMusic,
+			want: filepath.FromSlash("/mnt/music"),
+		},
+		{
+			name:    "relative_path",
+			dir:     UserDirPictures,
+			wantErr: true,
+		},
+		{
+			name: "trailing_comment",
+			dir:  UserDirVideos,
+			want: filepath.Join(home, "Videos"),
+		},
+		{
+			name: "env_var_override",
+			dir:  UserDirDesktop,
+			env:  "/abs/desktop",
+			want: filepath.FromSlash("/abs/desktop"),
+		},
+		{
+			name: "lowercase_name",
+			dir:  "videos",
+			want: filepath.Join(home, "Videos"),
+		},
+		{
+			name: "missing_entry",
+			dir:  UserDirTemplates,
+			want: hostDefaults().UserDir(MustHomeDir(), UserDirTemplates),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(userDirKey(UserDirDesktop), tt.env)
+
+			got, err := UserDir(tt.dir)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("UserDir(%q) error = %v, wantErr %v", tt.dir, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("UserDir(%q) = %q, want %q", tt.dir, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUserDirWithoutFile(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv(userDirKey(UserDirDownload), "")
+
+	got, err := DownloadDir()
+	if err != nil {
+		t.Errorf("DownloadDir() error = %v", err)
+	}
+	if want := hostDefaults().UserDir(MustHomeDir(), UserDirDownload); got != want {
+		t.Errorf("DownloadDir() = %q, want %q", got, want)
+	}
+}
+
+func TestParseUserDirsLine(t *testing.T) {
+	tests := []struct {
+		name      string
+		line      string
+		wantKey   string
+		wantEntry userDirsEntry
+		wantOK    bool
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name: "comment",
+			line: `# XDG_DESKTOP_DIR="$HOME/Desktop"`,
+		},
+		{
+			name: "unrelated_variable",
+			line: `FOO="bar"`,
+		},
+		{
+			name:      "leading_whitespace",
+			line:      `  XDG_DESKTOP_DIR="$HOME/Desktop"`,
+			wantKey:   "XDG_DESKTOP_DIR",
+			wantEntry: userDirsEntry{path: "/Desktop", underHome: true},
+			wantOK:    true,
+		},
+		{
+			name:      "unquoted_with_escapes",
+			line:      `XDG_MUSIC_DIR=$HOME/My\ Music`,
+			wantKey:   "XDG_MUSIC_DIR",
+			wantEntry: userDirsEntry{path: "/My Music", underHome: true},
+			wantOK:    true,
+		},
+		{
+			name:      "mixed_quotes",
+			line:      `XDG_MUSIC_DIR="$HOME/"'It'"'"'s'`,
+			wantKey:   "XDG_MUSIC_DIR",
+			wantEntry: userDirsEntry{path: "/It's", underHome: true},
+			wantOK:    true,
+		},
+		{
+			name:      "literal_backslash_in_double_quotes",
+			line:      `XDG_MUSIC_DIR="$HOME/a\b"`,
+			wantKey:   "XDG_MUSIC_DIR",
+			wantEntry: userDirsEntry{path: `/a\b`, underHome: true},
+			wantOK:    true,
+		},
+		{
+			name:      "home_only",
+			line:      `XDG_DESKTOP_DIR="$HOME"`,
+			wantKey:   "XDG_DESKTOP_DIR",
+			wantEntry: userDirsEntry{underHome: true},
+			wantOK:    true,
+		},
+		{
+			name:      "escaped_home",
+			line:      `XDG_DESKTOP_DIR="\$HOME/Desktop"`,
+			wantKey:   "XDG_DESKTOP_DIR",
+			wantEntry: userDirsEntry{path: "$HOME/Desktop"},
+			wantOK:    true,
+		},
+		{
+			name:      "other_variables",
+			line:      `XDG_DESKTOP_DIR="$HOMEDIR/$USER"`,
+			wantKey:   "XDG_DESKTOP_DIR",
+			wantEntry: userDirsEntry{path: "$HOMEDIR/$USER"},
+			wantOK:    true,
+		},
+		{
+			name: "unterminated_quote",
+			line: `XDG_MUSIC_DIR="$HOME/Music`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, entry, ok := parseUserDirsLine(tt.line)
+			if key != tt.wantKey || entry != tt.wantEntry || ok != tt.wantOK {
+				t.Errorf("parseUserDirsLine(%q) = (%q, %+v, %v), want (%q, %+v, %v)",
+					tt.line, key, entry, ok, tt.wantKey, tt.wantEntry, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestSetUserDir(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	t.Setenv(userDirKey(UserDirDownload), "")
+	t.Setenv(userDirKey(UserDirMusic), "")
+
+	config := filepath.Join(t.TempDir(), "config")
+	t.Setenv("XDG_CONFIG_HOME", config)
+	if err := os.MkdirAll(config, NewDirectoryPermissions); err != nil {
+		t.Fatal(err)
+	}
+
+	content := `# Comment.
+XDG_DOWNLOAD_DIR="$HOME/Downloads"
+XDG_CUSTOM_DIR="$HOME/Custom"
+XDG_DOWNLOAD_DIR="$HOME/Duplicate"
+`
+	filePath := filepath.Join(config, userDirsFileName)
+	if err := os.WriteFile(filePath, []byte(content), NewFilePermissions); err != nil {
+		t.Fatal(err)
+	}
+
+	download := filepath.Join(home, "big disk", "Downloads")
+	if err := SetUserDir(UserDirDownload, download, true); err != nil {
+		t.Fatalf("SetUserDir() error = %v", err)
+	}
+	if !absDirExists(download) {
+		t.Errorf("SetUserDir() did not create %q", download)
+	}
+
+	music := filepath.Join(t.TempDir(), "Music")
+	if err := SetUserDir(UserDirMusic, music, false); err != nil {
+		t.Fatalf("SetUserDir() error = %v", err)
+	}
+
+	got, err := os.ReadFile(filePath) //gosec:disable G304
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `# Comment.
+XDG_DOWNLOAD_DIR="$HOME/big disk/Downloads"
+XDG_CUSTOM_DIR="$HOME/Custom"
+XDG_MUSIC_DIR="` + music + `"
+`
+	if string(got) != want {
+		t.Errorf("user-dirs.dirs = %q, want %q", got, want)
+	}
+
+	if got, err := DownloadDir(); err != nil || got != download {
+		t.Errorf("DownloadDir() = %q, %v, want %q", got, err, download)
+	}
+	if got, err := MusicDir(); err != nil || got != music {
+		t.Errorf("MusicDir() = %q, %v, want %q", got, err, music)
+	}
+
+	if err := SetUserDir(UserDirMusic, "relative/path", false); err == nil {
+		t.Error("SetUserDir() with relative path error = nil, want error")
+	}
+}
+
+func TestSetUserDirRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	r := &Resolver{Getenv: mapEnv(map[string]string{
+		"HOME":            filepath.Join(dir, "home"),
+		"USERPROFILE":     filepath.Join(dir, "home"),
+		"XDG_CONFIG_HOME": filepath.Join(dir, "config"),
+	})}
+
+	tests := []struct {
+		name string
+		path string
+	}{
+		{name: "outside_home", path: filepath.Join(dir, "mnt", "$data")},
+		{name: "under_home", path: filepath.Join(dir, "home", `$HOME "quoted"`)},
+		{name: "backtick_and_braces", path: filepath.Join(dir, "mnt", "a`b$"+"{USER}")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := r.SetUserDir(UserDirMusic, tt.path, false); err != nil {
+				t.Fatalf("SetUserDir() error = %v", err)
+			}
+
+			got, err := r.UserDir(UserDirMusic)
+			if err != nil {
+				t.Fatalf("UserDir() error = %v", err)
+			}
+			if got != tt.path {
+				t.Errorf("UserDir() = %q, want %q", got, tt.path)
+			}
+		})
+	}
+}
+
+func TestUserDirsValue(t *testing.T) {
+	home := filepath.FromSlash("/home/user")
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	tests := []struct {
+		name string
+		path string
+		want string
+	}{
+		{
+			name: "home",
+			path: home,
+			want: `"$HOME"`,
+		},
+		{
+			name: "under_home",
+			path: filepath.Join(home, "Downloads"),
+			want: `"$HOME/Downloads"`,
+		},
+		{
+			name: "outside_home",
+			path: filepath.FromSlash("/mnt/disk"),
+			want: `"` + filepath.FromSlash("/mnt/disk") + `"`,
+		},
+		{
+			name: "special_characters",
+			path: filepath.Join(home, `a"b$c`),
+			want: `"$HOME/a\"b\$c"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := defaultResolver.userDirsValue(tt.path)
+			if err != nil {
+				t.Errorf("userDirsValue(%q) error = %v", tt.path, err)
+			}
+			if got != tt.want {
+				t.Errorf("userDirsValue(%q) = %s, want %s", tt.path, got, tt.want)
+			}
+		})
+	}
+}
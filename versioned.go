@@ -0,0 +1,232 @@
+package xdg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	currentVersionFile = metadataFilePrefix + "current-version"
+	versionMarkerFile  = metadataFilePrefix + "version"
+	versionLockPrefix  = metadataFilePrefix + "version-"
+)
+
+// VersionedCache is an app's cache directory for a specific version key,
+// which was opened by [OpenVersionedCache], and should be closed by
+// [VersionedCache.Close] when the app stops using it.
+type VersionedCache struct {
+	// Path is the full path of the directory: "<CacheHome>/<app>/<key>".
+	Path string
+	// Key identifies the format of the cache's content, e.g. "v2".
+	Key string
+
+	lock *FileLock
+}
+
+// OpenVersionedCache returns the given app's cache directory for the given
+// version key, under [CacheHome]. It creates the directory if it doesn't exist
+// yet, and atomically makes it the current version of the app's cache.
+//
+// The version key should change whenever the format of the cache's content
+// changes (e.g. when upgrading a tool), so that newer versions of the app
+// don't read incompatible content. Directories of other keys are removed by
+// [CollectVersionedCaches], unless they are still open in other processes.
+//
+// This function waits until a concurrent [CollectVersionedCaches] is done with
+// the same key, or the context is done. Use [context.WithTimeout] to limit the waiting time.
+//
+// Note: this function normalizes the app name and ensures it doesn't contain
+// path elements, but the caller is responsible for input vetting. The version
+// key must not contain path elements or start with ".", and it's not normalized.
+func OpenVersionedCache(ctx context.Context, appName, key string) (*VersionedCache, error) {
+	return defaultResolver.OpenVersionedCache(ctx, appName, key)
+}
+
+// OpenVersionedCache is like the package-level [OpenVersionedCache], but uses r's environment.
+func (r *Resolver) OpenVersionedCache(ctx context.Context, appName, key string) (*VersionedCache, error) {
+	if err := r.checkHost(); err != nil {
+		return nil, err
+	}
+	return openVersionedCache(ctx, r.CacheHome, appName, key)
+}
+
+func openVersionedCache(
+	ctx context.Context,
+	dirType func() (string, error),
+	appName, key string,
+) (*VersionedCache, error) {
+	if err := checkVersionKey(key); err != nil {
+		return nil, err
+	}
+
+	// A shared lock prevents the removal of the directory while it's open.
+	l, err := LockFile(ctx, dirType, appName, versionLockPrefix+key, false)
+	if err != nil {
+		return nil, err
+	}
+
+	path, err := CreateSubdir(dirType, appName, key)
+	if err != nil {
+		return nil, errors.Join(err, l.Unlock())
+	}
+
+	if err := writeFileAtomic(path, versionMarkerFile, []byte(key+"\n"), NewFilePermissions); err != nil {
+		return nil, errors.Join(err, l.Unlock())
+	}
+
+	appDir := filepath.Dir(path)
+	if current, _ := readVersionFile(appDir, currentVersionFile); current != key {
+		if err := writeFileAtomic(appDir, currentVersionFile, []byte(key+"\n"), NewFilePermissions); err != nil {
+			return nil, errors.Join(err, l.Unlock())
+		}
+	}
+
+	return &VersionedCache{Path: path, Key: key, lock: l}, nil
+}
+
+// Close releases the directory, so that [CollectVersionedCaches]
+// may remove it if it's no longer the current version.
+func (c *VersionedCache) Close() error {
+	if c.lock == nil {
+		return nil
+	}
+
+	err := c.lock.Unlock()
+	c.lock = nil
+	return err
+}
+
+// CollectVersionedCaches removes the cache directories of the given app under
+// [CacheHome] which were created by [OpenVersionedCache] with version keys
+// other than the current one. It skips directories that are still open in
+// other processes, and returns the paths of the removed ones.
+//
+// This function can be called on demand, or in the background with
+// a goroutine, after calling [OpenVersionedCache] with a new key.
+// It stops removing directories when the context is done.
+//
+// Note 1: this function does not remove the ".xdg-version-<key>.lock" files of
+// the removed directories, because another process may be waiting to lock the
+// same file in [OpenVersionedCache]: removing it would let that process and a
+// later one hold conflicting locks on different files. These files are empty.
+//
+// Note 2: this function normalizes the app name and ensures it doesn't
+// contain path elements, but the caller is responsible for input vetting.
+func CollectVersionedCaches(ctx context.Context, appName string) ([]string, error) {
+	return defaultResolver.CollectVersionedCaches(ctx, appName)
+}
+
+// CollectVersionedCaches is like the package-level [CollectVersionedCaches], but uses r's environment.
+func (r *Resolver) CollectVersionedCaches(ctx context.Context, appName string) ([]string, error) {
+	if err := r.checkHost(); err != nil {
+		return nil, err
+	}
+	return collectVersionedCaches(ctx, r.CacheHome, appName)
+}
+
+func collectVersionedCaches(ctx context.Context, dirType func() (string, error), appName string) ([]string, error) {
+	appDir, err := CreateDir(dirType, appName)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(appDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var removed []string
+	var errs []error
+	for _, e := range entries {
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, err)
+			break
+		}
+		if !e.IsDir() {
+			continue
+		}
+
+		path := filepath.Join(appDir, e.Name())
+		ok, err := removeVersionedCache(dirType, appName, path, e.Name())
+		if err != nil {
+			errs = append(errs, err)
+		}
+		if ok {
+			removed = append(removed, path)
+		}
+	}
+
+	return removed, errors.Join(errs...)
+}
+
+// removeVersionedCache removes a directory which was created by
+// [OpenVersionedCache], if it's not the current version, and it's not open.
+func removeVersionedCache(dirType func() (string, error), appName, path, key string) (bool, error) {
+	if marker, err := readVersionFile(path, versionMarkerFile); err != nil || marker != key {
+		return false, nil // Not a versioned cache directory.
+	}
+
+	l, err := TryLockFile(dirType, appName, versionLockPrefix+key, true)
+	if err != nil {
+		if errors.Is(err, ErrLocked) {
+			return false, nil // Still open in another process.
+		}
+		return false, err
+	}
+	defer func() { _ = l.Unlock() }()
+
+	// Check again, now that the directory can't be opened concurrently.
+	current, err := readVersionFile(filepath.Dir(path), currentVersionFile)
+	if err != nil || current == key {
+		return false, err
+	}
+
+	if err := os.RemoveAll(path); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// readVersionFile reads a version key from the given file. It returns an
+// empty string if the file doesn't exist, and an error if it's malformed.
+func readVersionFile(dir, name string) (string, error) {
+	root, err := os.OpenRoot(dir)
+	if err != nil {
+		return "", err
+	}
+	defer root.Close()
+
+	data, err := root.ReadFile(name)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return "", nil
+		}
+		return "", err
+	}
+
+	key := strings.TrimSuffix(string(data), "\n")
+	if err := checkVersionKey(key); err != nil {
+		return "", fmt.Errorf("invalid version file %q: %w", filepath.Join(dir, name), err)
+	}
+	return key, nil
+}
+
+// checkVersionKey ensures that a version key is a plain directory name,
+// which doesn't collide with other files in the app's cache directory.
+func checkVersionKey(key string) error {
+	switch {
+	case key == "":
+		return errors.New("version key is empty")
+	case strings.HasPrefix(key, "."):
+		return errors.New("version key must not start with '.'")
+	case strings.ContainsAny(key, `/\`) || key != filepath.Clean(key):
+		return errors.New("version key must not contain path elements")
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,113 @@
+package xdg
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestVersionedCache(t *testing.T) {
+	dir := t.TempDir()
+	dirType := dirForTest(dir, nil)
+	appDir := filepath.Join(dir, "my_app")
+
+	v1, err := openVersionedCache(t.Context(), dirType, "my_app", "v1")
+	if err != nil {
+		t.Fatalf("openVersionedCache() error = %v", err)
+	}
+	if want := filepath.Join(appDir, "v1"); v1.Path != want {
+		t.Errorf("openVersionedCache() Path = %q, want %q", v1.Path, want)
+	}
+	if err := os.WriteFile(filepath.Join(v1.Path, "data"), []byte("old"), NewFilePermissions); err != nil {
+		t.Fatal(err)
+	}
+
+	// An unrelated directory, which must not be removed.
+	if err := os.Mkdir(filepath.Join(appDir, "other"), NewDirectoryPermissions); err != nil {
+		t.Fatal(err)
+	}
+
+	v2, err := openVersionedCache(t.Context(), dirType, "my_app", "v2")
+	if err != nil {
+		t.Fatalf("openVersionedCache() error = %v", err)
+	}
+	defer v2.Close()
+
+	if current, err := readVersionFile(appDir, currentVersionFile); err != nil || current != "v2" {
+		t.Errorf("current version = %q, %v, want %q", current, err, "v2")
+	}
+
+	// v1 is still open, so it must not be removed.
+	removed, err := collectVersionedCaches(t.Context(), dirType, "my_app")
+	if err != nil {
+		t.Fatalf("collectVersionedCaches() error = %v", err)
+	}
+	if len(removed) > 0 {
+		t.Errorf("collectVersionedCaches() with open cache = %q, want none", removed)
+	}
+
+	if err := v1.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	removed, err = collectVersionedCaches(t.Context(), dirType, "my_app")
+	if err != nil {
+		t.Fatalf("collectVersionedCaches() error = %v", err)
+	}
+	if want := []string{v1.Path}; !slices.Equal(removed, want) {
+		t.Errorf("collectVersionedCaches() = %q, want %q", removed, want)
+	}
+
+	for _, path := range []string{v2.Path, filepath.Join(appDir, "other")} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("collectVersionedCaches() removed %q: %v", path, err)
+		}
+	}
+}
+
+func TestCheckVersionKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     string
+		wantErr bool
+	}{
+		{
+			name: "valid",
+			key:  "v1.2.3",
+		},
+		{
+			name:    "empty",
+			key:     "",
+			wantErr: true,
+		},
+		{
+			name:    "hidden",
+			key:     ".v1",
+			wantErr: true,
+		},
+		{
+			name:    "slash",
+			key:     "v1/v2",
+			wantErr: true,
+		},
+		{
+			name:    "backslash",
+			key:     `v1\v2`,
+			wantErr: true,
+		},
+		{
+			name:    "parent",
+			key:     "..",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := checkVersionKey(tt.key); (err != nil) != tt.wantErr {
+				t.Errorf("checkVersionKey() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package xdg
+
+import (
+	"crypto/rand"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// WriteFile writes data to the given app's file under the given XDG base
+// directory, atomically: readers see either the old content or the new
+// content, but never a partial write, even if the process crashes. It
+// creates any parent directories that don't exist yet, and the file
+// is created or replaced with [NewFilePermissions].
+//
+// Note: this function normalizes the app and file names and ensures they
+// don't contain path elements, but the caller is responsible for input vetting.
+func WriteFile(dirType func() (string, error), appName, fileName string, data []byte) (string, error) {
+	fileName = filepath.Clean(fileName)
+	if fileName == "." {
+		return "", errors.New("file name is empty")
+	}
+	if strings.Contains(fileName, pathSep) {
+		return "", errors.New("file name must not contain separator")
+	}
+
+	return WriteFilePath(dirType, appName, fileName, data)
+}
+
+// WriteFilePath is like [WriteFile], but the file may be in a subdirectory.
+//
+// Note 1: this function normalizes the app name and ensures it doesn't
+// contain path elements, but the caller is responsible for input vetting.
+//
+// Note 2: the filePath parameter must contain at least a filename, and may contain a prefix of
+// 0 or more path elements. This function ensures that it does not escape the app's directory.
+func WriteFilePath(dirType func() (string, error), appName, filePath string, data []byte) (string, error) {
+	if _, file := filepath.Split(filePath); file == "" {
+		return "", errors.New("file path must end with a file name")
+	}
+
+	filePath = filepath.Clean(filePath)
+	if filePath == "." {
+		return "", errors.New("file path is empty")
+	}
+
+	subpath, file := filepath.Split(filePath)
+	path, err := CreateSubdir(dirType, appName, subpath)
+	if err != nil {
+		return "", err
+	}
+
+	if err := writeFileAtomic(path, file, data, NewFilePermissions); err != nil {
+		return "", err
+	}
+
+	return filepath.Join(path, file), nil
+}
+
+// writeFileAtomic writes data to a temporary file in the given directory, flushes
+// it to stable storage, renames it to the given file name, and then flushes the
+// directory too. All the file operations are confined to the given directory.
+func writeFileAtomic(dir, file string, data []byte, perm os.FileMode) error {
+	root, err := os.OpenRoot(dir)
+	if err != nil {
+		return err
+	}
+	defer root.Close()
+
+	tempName := "." + file + ".tmp" + rand.Text()
+	f, err := root.OpenFile(tempName, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = root.Remove(tempName) }() // No-op after a successful rename.
+
+	if _, err := f.Write(data); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	// Override the effect of the process's umask.
+	if err := root.Chmod(tempName, perm); err != nil {
+		return err
+	}
+
+	if err := root.Rename(tempName, file); err != nil {
+		return err
+	}
+
+	return syncDir(root)
+}
+
+// syncDir flushes a directory to stable storage, to persist renames in it.
+// This is not supported in Windows, where renames are persisted anyway.
+func syncDir(root *os.Root) error {
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+
+	d, err := root.Open(".")
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+
+	return d.Sync()
+}
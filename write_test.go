@@ -0,0 +1,144 @@
+package xdg
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestWriteFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		appName  string
+		fileName string
+		wantErr  bool
+	}{
+		{
+			name:     "happy_path",
+			appName:  "my_app",
+			fileName: "my_file",
+		},
+		{
+			name:     "empty_app_name",
+			fileName: "my_file",
+			wantErr:  true,
+		},
+		{
+			name:     "insecure_app_name",
+			appName:  "../other_app",
+			fileName: "my_file",
+			wantErr:  true,
+		},
+		{
+			name:    "empty_file_name",
+			appName: "my_app",
+			wantErr: true,
+		},
+		{
+			name:     "insecure_file_name",
+			appName:  "my_app",
+			fileName: "../other_app/other_file",
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := WriteFile(tempDir(t), tt.appName, tt.fileName, []byte("data"))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("WriteFile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && got != "" {
+				t.Errorf("WriteFile() = %q, want %q", got, "")
+			}
+		})
+	}
+}
+
+func TestWriteFilePath(t *testing.T) {
+	tests := []struct {
+		name     string
+		filePath string
+		wantErr  bool
+	}{
+		{
+			name:     "happy_path_with_0_subdirs",
+			filePath: "my_file",
+		},
+		{
+			name:     "happy_path_with_3_subdirs",
+			filePath: "subdir1/subdir2/subdir3/my_file",
+		},
+		{
+			name:     "insecure_file_path",
+			filePath: "../other_app/other_file",
+			wantErr:  true,
+		},
+		{
+			name:     "root_file_path",
+			filePath: "/my_file",
+			wantErr:  true,
+		},
+		{
+			name:     "trailing_slash_file_path",
+			filePath: "subdir/",
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := WriteFilePath(tempDir(t), "my_app", tt.filePath, []byte("data"))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("WriteFilePath() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			data, err := os.ReadFile(got) //gosec:disable G304
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != "data" {
+				t.Errorf("WriteFilePath() content = %q, want %q", data, "data")
+			}
+		})
+	}
+}
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "file")
+
+	for _, content := range []string{"old", "new"} {
+		if err := writeFileAtomic(dir, "file", []byte(content), NewFilePermissions); err != nil {
+			t.Fatalf("writeFileAtomic() error = %v", err)
+		}
+
+		got, err := os.ReadFile(path) //gosec:disable G304
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != content {
+			t.Errorf("writeFileAtomic() content = %q, want %q", got, content)
+		}
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); runtime.GOOS != "windows" && perm != NewFilePermissions {
+		t.Errorf("writeFileAtomic() permissions = %#o, want %#o", perm, NewFilePermissions)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("writeFileAtomic() left %d files in the directory, want 1", len(entries))
+	}
+}